@@ -1,28 +1,382 @@
+// Package config loads the configuration of the application. Every setting
+// is looked up in the following sources, each overriding the previous ones:
+//
+//  1. the defaults of the settings,
+//  2. the YAML (.yaml, .yml) or TOML (.toml) file named by --config or
+//     CONFIG_FILE, where keys may be nested (server: {read_timeout: 5s}),
+//  3. the .env.<APP_ENV> file of the working directory, when it exists,
+//  4. environment variables,
+//  5. command-line flags such as --server-read-timeout=5s.
+//
+// Every value is then validated and all problems are reported at once.
 package config
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/database"
 	"github.com/tarkanaciksoz/api-todo-app/internal/model"
 )
 
-func Init(logger *log.Logger) model.Config {
-	appEnv := os.Getenv("APP_ENV")
-	if appEnv == "" {
-		logger.Printf("You must declare APP_ENV before run")
-		os.Exit(1)
+const (
+	sourceDefault = "default"
+	sourceEnv     = "environment"
+	sourceFlag    = "flag"
+)
+
+const redacted = "[REDACTED]"
+
+// ValidationErrors lists every problem found in the configuration.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// value is the raw value of a setting and the source it was taken from.
+type value struct {
+	raw    string
+	source string
+}
+
+// Loaded is a validated configuration along with the raw values it was built
+// from.
+type Loaded struct {
+	Config model.Config
+	// PrintConfig is set by --print-config: the effective configuration
+	// should be printed instead of serving.
+	PrintConfig bool
+
+	values map[string]value
+}
+
+// Load reads the configuration from the sources described in the package
+// documentation. args are the command-line arguments without the program
+// name and environ the environment as returned by os.Environ. It returns
+// flag.ErrHelp when help was requested and ValidationErrors when any value is
+// invalid.
+func Load(args []string, environ []string) (*Loaded, error) {
+	env := map[string]string{}
+	for _, entry := range environ {
+		if key, v, ok := strings.Cut(entry, "="); ok {
+			env[key] = v
+		}
+	}
+
+	flags := flag.NewFlagSet("api-todo-app", flag.ContinueOnError)
+	configFile := flags.String("config", "", "YAML or TOML configuration `file` (env CONFIG_FILE)")
+	printConfig := flags.Bool("print-config", false, "print the effective configuration, secrets redacted, and exit")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		usage := s.usage + " (env " + s.key
+		if s.def != "" {
+			usage += ", default " + s.def
+		}
+		flagValues[s.key] = flags.String(flagName(s.key), "", usage+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]value, len(settings))
+	for _, s := range settings {
+		values[s.key] = value{raw: s.def, source: sourceDefault}
+	}
+
+	errs := ValidationErrors{}
+
+	path := *configFile
+	if path == "" {
+		path = env["CONFIG_FILE"]
+	}
+	if path != "" {
+		fromFile, err := readFile(path)
+		if err != nil {
+			return nil, ValidationErrors{err}
+		}
+		for key, raw := range fromFile {
+			if _, ok := lookup(key); !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown setting %s", path, strings.ToLower(key)))
+				continue
+			}
+			values[key] = value{raw: raw, source: path}
+		}
+	}
+
+	// APP_ENV picks the .env file, so it is resolved from the other sources
+	// first.
+	appEnv := values["APP_ENV"].raw
+	if v, ok := env["APP_ENV"]; ok {
+		appEnv = v
+	}
+	if flagSet(flags, "APP_ENV") {
+		appEnv = *flagValues["APP_ENV"]
+	}
+	if appEnv != "" {
+		dotenv := ".env." + appEnv
+		if _, err := os.Stat(dotenv); err == nil {
+			fromDotenv, err := godotenv.Read(dotenv)
+			if err != nil {
+				return nil, ValidationErrors{fmt.Errorf("%s: %w", dotenv, err)}
+			}
+			overlay(values, fromDotenv, dotenv)
+		}
+	}
+
+	overlay(values, env, sourceEnv)
+	flags.Visit(func(f *flag.Flag) {
+		if key := settingKey(f.Name); key != "" {
+			values[key] = value{raw: f.Value.String(), source: sourceFlag}
+		}
+	})
+
+	config := model.Config{}
+	for _, s := range settings {
+		v := values[s.key]
+		if err := s.apply(&config, v.raw); err != nil {
+			shown := strconv.Quote(v.raw)
+			if s.secret {
+				shown = redacted
+			}
+			errs = append(errs, fmt.Errorf("%s=%s (from %s): %w", s.key, shown, v.source, err))
+		}
+	}
+	errs = append(errs, validate(config)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &Loaded{Config: config, PrintConfig: *printConfig, values: values}, nil
+}
+
+// Print writes the effective value of every setting with its source, in the
+// .env format. Secrets and the password of DSNs are redacted.
+func (l *Loaded) Print(w io.Writer) error {
+	for _, s := range settings {
+		v := l.values[s.key]
+
+		raw := v.raw
+		switch {
+		case s.secret && raw != "":
+			raw = redacted
+		case s.key == "DB_DSN":
+			raw = redactDSN(raw)
+		}
+		if strings.ContainsAny(raw, " #\"'") {
+			raw = strconv.Quote(raw)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s=%s # %s\n", s.key, raw, v.source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate checks the settings that depend on each other.
+func validate(c model.Config) []error {
+	errs := []error{}
+
+	if c.AppEnv == "" {
+		errs = append(errs, errors.New("APP_ENV must be set"))
+	}
+	if c.InternalBindAddress == c.BindAddress {
+		errs = append(errs, errors.New("INTERNAL_BIND_ADDRESS must differ from BIND_ADDRESS"))
+	}
+
+	switch c.DBDriver {
+	case "memory":
+	case database.DriverSQLite, database.DriverPostgres:
+		if c.DBDSN == "" {
+			errs = append(errs, fmt.Errorf("DB_DSN must be set for DB_DRIVER %s", c.DBDriver))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("DB_DRIVER %q is not one of memory, sqlite or postgres", c.DBDriver))
 	}
 
-	err := godotenv.Load(".env" + "." + appEnv)
+	if _, err := auth.NewJWT(c); err != nil {
+		errs = append(errs, fmt.Errorf("JWT_ALGORITHM %s: %w", c.JWTAlgorithm, err))
+	}
+
+	if c.CORS.AllowCredentials {
+		for _, origin := range c.CORS.AllowedOrigins {
+			if origin == "*" {
+				errs = append(errs, errors.New("CORS_ALLOW_CREDENTIALS cannot be used with the * origin of CORS_ALLOWED_ORIGINS"))
+			}
+		}
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES entry %q is neither an IP nor a CIDR", proxy))
+		}
+	}
+
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT %q is not one of json or text", c.LogFormat))
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER %q is not one of none, stdout or otlp", c.Tracing.Exporter))
+	}
+
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if c.Server.TLS.ClientCAFile != "" && c.Server.TLS.CertFile == "" {
+		errs = append(errs, errors.New("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE"))
+	}
+
+	if c.ShutdownTimeout == 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if c.TokenTTL == 0 {
+		errs = append(errs, errors.New("TOKEN_TTL must be positive"))
+	}
+
+	return errs
+}
+
+// overlay replaces values by those of the known settings found in from.
+func overlay(values map[string]value, from map[string]string, source string) {
+	for _, s := range settings {
+		if raw, ok := from[s.key]; ok {
+			values[s.key] = value{raw: raw, source: source}
+		}
+	}
+}
+
+// readFile reads a YAML or TOML file into the raw values of its settings,
+// keyed like environment variables.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("%s: want a .yaml, .yml or .toml file", path)
+	}
 	if err != nil {
-		logger.Printf("Error while Read .env file: %s\n", err.Error())
-		os.Exit(1)
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", tree, values)
+	return values, nil
+}
+
+// flatten joins nested keys with underscores, so that read_timeout under
+// server becomes SERVER_READ_TIMEOUT. Lists become comma separated values and
+// tables of pairs settings become "<name>=<value>;..." values.
+func flatten(prefix string, tree map[string]interface{}, values map[string]string) {
+	for name, v := range tree {
+		key := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if s, ok := lookup(key); ok && s.pairs {
+				pairs := make([]string, 0, len(v))
+				for name, pair := range v {
+					pairs = append(pairs, name+"="+fmt.Sprint(pair))
+				}
+				sort.Strings(pairs)
+				values[key] = strings.Join(pairs, ";")
+				continue
+			}
+			flatten(key, v, values)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// dsnPassword matches the password of keyword/value connection strings such
+// as "host=db password=secret", quoted or not.
+var dsnPassword = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S*)`)
+
+// redactDSN hides the password of connection strings, whether URLs, where it
+// may also be a query parameter, or keyword/value pairs.
+func redactDSN(dsn string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsnPassword.ReplaceAllString(dsn, "${1}xxxxx")
 	}
 
-	return model.Config{
-		AppEnv:      appEnv,
-		BindAddress: ":" + os.Getenv("BIND_ADDRESS"),
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return redacted
 	}
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", "xxxxx")
+		u.RawQuery = query.Encode()
+	}
+
+	return u.Redacted()
+}
+
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+func settingKey(flagName string) string {
+	key := strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+	if _, ok := lookup(key); !ok {
+		return ""
+	}
+
+	return key
+}
+
+func flagSet(flags *flag.FlagSet, key string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == flagName(key)
+	})
+
+	return set
 }
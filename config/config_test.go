@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+const testSecret = "config-test-secret-of-at-least-32-bytes"
+
+type ConfigSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	dir string
+	wd  string
+}
+
+func TestConfigSuite(t *testing.T) {
+	suite.Run(t, new(ConfigSuite))
+}
+
+// SetupTest moves into an empty directory so that .env files of the
+// repository are not read.
+func (s *ConfigSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.dir = s.T().TempDir()
+
+	var err error
+	s.wd, err = os.Getwd()
+	s.NoError(err)
+	s.NoError(os.Chdir(s.dir))
+}
+
+func (s *ConfigSuite) TearDownTest() {
+	s.NoError(os.Chdir(s.wd))
+}
+
+func (s *ConfigSuite) write(name, content string) string {
+	path := filepath.Join(s.dir, name)
+	s.NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func (s *ConfigSuite) TestLoadGivenWhenSourcesAreMerged() {
+	s.T().Run("TestLoadGivenOnlyRequiredValuesWhenItIsLoadedThenTheDefaultsShouldApply", func(t *testing.T) {
+		loaded, err := Load(nil, []string{"APP_ENV=test", "JWT_SECRET=" + testSecret})
+
+		s.NoError(err)
+		s.Equal(":9090", loaded.Config.BindAddress)
+		s.Equal(":9091", loaded.Config.InternalBindAddress)
+		s.Equal("memory", loaded.Config.DBDriver)
+		s.Equal(5*time.Second, loaded.Config.RequestTimeout)
+		s.Equal(model.RateLimit{Requests: 120, Per: time.Minute}, loaded.Config.RateLimit.Default)
+		s.Equal([]string{"GET", "POST", "PUT", "PATCH", "DELETE"}, loaded.Config.CORS.AllowedMethods)
+		s.True(loaded.Config.Server.HTTP2)
+		s.Equal(1.0, loaded.Config.Tracing.SampleRatio)
+	})
+
+	s.T().Run("TestLoadGivenEverySourceWhenItIsLoadedThenEachShouldOverrideThePreviousOnes", func(t *testing.T) {
+		file := s.write("config.yaml", `
+app_env: test
+jwt_secret: `+testSecret+`
+request_timeout: 1s
+token_ttl: 1h
+log_level: error
+server:
+  read_timeout: 1s
+  write_timeout: 1s
+rate_limit_routes:
+  LOGIN USER: 10/1m
+cors_allowed_origins:
+  - https://app.example.com
+  - https://*.example.org
+`)
+		s.write(".env.test", "TOKEN_TTL=2h\nLOG_LEVEL=warn\nSERVER_WRITE_TIMEOUT=2s\n")
+
+		loaded, err := Load(
+			[]string{"--config", file, "--server-write-timeout=4s"},
+			[]string{"LOG_LEVEL=debug", "SERVER_WRITE_TIMEOUT=3s", "UNRELATED=1"},
+		)
+
+		s.NoError(err)
+		s.Equal(time.Second, loaded.Config.RequestTimeout)
+		s.Equal(time.Second, loaded.Config.Server.ReadTimeout)
+		s.Equal(2*time.Hour, loaded.Config.TokenTTL)
+		s.Equal("DEBUG", loaded.Config.LogLevel.String())
+		s.Equal(4*time.Second, loaded.Config.Server.WriteTimeout)
+		s.Equal(map[string]model.RateLimit{"LOGIN USER": {Requests: 10, Per: time.Minute}}, loaded.Config.RateLimit.Routes)
+		s.Equal([]string{"https://app.example.com", "https://*.example.org"}, loaded.Config.CORS.AllowedOrigins)
+	})
+
+	s.T().Run("TestLoadGivenTOMLFileInTheEnvironmentWhenItIsLoadedThenItShouldBeRead", func(t *testing.T) {
+		file := s.write("config.toml", `
+app_env = "test"
+jwt_secret = "`+testSecret+`"
+bind_address = "127.0.0.1:8080"
+
+[server]
+http2 = false
+max_header_bytes = 4096
+`)
+
+		loaded, err := Load(nil, []string{"CONFIG_FILE=" + file})
+
+		s.NoError(err)
+		s.Equal("127.0.0.1:8080", loaded.Config.BindAddress)
+		s.False(loaded.Config.Server.HTTP2)
+		s.Equal(4096, loaded.Config.Server.MaxHeaderBytes)
+	})
+
+	s.T().Run("TestLoadGivenAppEnvFlagWhenItIsLoadedThenItShouldSelectTheEnvFile", func(t *testing.T) {
+		s.write(".env.staging", "JWT_SECRET="+testSecret+"\nDB_DRIVER=sqlite\nDB_DSN=staging.db\n")
+
+		loaded, err := Load([]string{"--app-env=staging"}, []string{"APP_ENV=prod"})
+
+		s.NoError(err)
+		s.Equal("staging", loaded.Config.AppEnv)
+		s.Equal("staging.db", loaded.Config.DBDSN)
+	})
+}
+
+func (s *ConfigSuite) TestLoadGivenWhenValuesAreInvalid() {
+	s.T().Run("TestLoadGivenSeveralInvalidValuesWhenItIsLoadedThenEveryProblemShouldBeReported", func(t *testing.T) {
+		_, err := Load([]string{"--rate-limit=fast"}, []string{
+			"REQUEST_TIMEOUT=soon",
+			"LOG_FORMAT=xml",
+			"DB_DRIVER=sqlite",
+			"TLS_CERT_FILE=cert.pem",
+			"INTERNAL_BIND_ADDRESS=9090",
+		})
+
+		validation := ValidationErrors{}
+		s.True(errors.As(err, &validation))
+		s.Contains(err.Error(), `REQUEST_TIMEOUT="soon" (from environment)`)
+		s.Contains(err.Error(), `RATE_LIMIT="fast" (from flag)`)
+		s.Contains(err.Error(), "APP_ENV must be set")
+		s.Contains(err.Error(), "JWT secret must be at least 32 bytes long")
+		s.Contains(err.Error(), `LOG_FORMAT "xml"`)
+		s.Contains(err.Error(), "DB_DSN must be set for DB_DRIVER sqlite")
+		s.Contains(err.Error(), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+		s.Contains(err.Error(), "INTERNAL_BIND_ADDRESS must differ from BIND_ADDRESS")
+		s.Len(validation, 8)
+	})
+
+	s.T().Run("TestLoadGivenInvalidSecretWhenItIsReportedThenItsValueShouldBeRedacted", func(t *testing.T) {
+		_, err := Load(nil, []string{"APP_ENV=test", "JWT_SECRET=short but secret"})
+
+		s.Error(err)
+		s.NotContains(err.Error(), "short but secret")
+	})
+
+	s.T().Run("TestLoadGivenUnknownKeyInTheFileWhenItIsLoadedThenItShouldBeReported", func(t *testing.T) {
+		file := s.write("typo.yaml", "app_env: test\njwt_secret: "+testSecret+"\nserver:\n  read_timout: 1s\n")
+
+		_, err := Load([]string{"--config=" + file}, nil)
+
+		s.ErrorContains(err, "unknown setting server_read_timout")
+	})
+
+	s.T().Run("TestLoadGivenUnsupportedFileWhenItIsLoadedThenItShouldReturnAnError", func(t *testing.T) {
+		_, err := Load([]string{"--config=" + s.write("config.json", "{}")}, nil)
+
+		s.ErrorContains(err, "want a .yaml, .yml or .toml file")
+	})
+}
+
+func (s *ConfigSuite) TestLoadGivenWhenTheConfigIsPrinted() {
+	s.T().Run("TestLoadGivenSecretsWhenTheConfigIsPrintedThenTheyShouldBeRedactedAndSourcesShown", func(t *testing.T) {
+		loaded, err := Load(
+			[]string{"--print-config", "--db-driver=postgres", "--db-dsn=postgres://todo:hunter2@db:5432/todo"},
+			[]string{"APP_ENV=test", "JWT_SECRET=" + testSecret},
+		)
+		s.NoError(err)
+		s.True(loaded.PrintConfig)
+
+		out := &bytes.Buffer{}
+		s.NoError(loaded.Print(out))
+
+		s.NotContains(out.String(), testSecret)
+		s.NotContains(out.String(), "hunter2")
+		s.Contains(out.String(), "JWT_SECRET=[REDACTED] # environment\n")
+		s.Contains(out.String(), "DB_DSN=postgres://todo:xxxxx@db:5432/todo # flag\n")
+		s.Contains(out.String(), "REQUEST_TIMEOUT=5s # default\n")
+	})
+
+	s.T().Run("TestLoadGivenKeywordValueDSNWhenTheConfigIsPrintedThenItsPasswordShouldBeRedacted", func(t *testing.T) {
+		loaded, err := Load(
+			[]string{"--db-driver=postgres", "--db-dsn=host=db user=todo password=s3cret dbname=todo"},
+			[]string{"APP_ENV=test", "JWT_SECRET=" + testSecret},
+		)
+		s.NoError(err)
+
+		out := &bytes.Buffer{}
+		s.NoError(loaded.Print(out))
+
+		s.NotContains(out.String(), "s3cret")
+		s.Contains(out.String(), `DB_DSN="host=db user=todo password=xxxxx dbname=todo" # flag`)
+	})
+
+	s.T().Run("TestRedactDSNGivenEveryFormWhenItIsCalledThenNoPasswordShouldRemain", func(t *testing.T) {
+		for dsn, expected := range map[string]string{
+			"host=db PASSWORD = 's3 \\'cret' dbname=todo":         "host=db PASSWORD = xxxxx dbname=todo",
+			"postgres://db:5432/todo?password=s3cret&sslmode=off": "postgres://db:5432/todo?password=xxxxx&sslmode=off",
+			"postgres://todo:s3cret@db:5432/todo":                 "postgres://todo:xxxxx@db:5432/todo",
+			"/app/data/todo.db":                                   "/app/data/todo.db",
+		} {
+			s.Equal(expected, redactDSN(dsn))
+		}
+	})
+}
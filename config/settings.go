@@ -0,0 +1,279 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// setting is one configuration value. Its key names the environment variable;
+// the file key and the flag are derived from it, so SERVER_READ_TIMEOUT is
+// also server_read_timeout (or read_timeout under server) in a file and
+// --server-read-timeout on the command line.
+type setting struct {
+	key   string
+	def   string
+	usage string
+	// secret values are never printed or echoed back in errors.
+	secret bool
+	// pairs values are written as "<name>=<value>;..." and may be given as a
+	// table in a file.
+	pairs bool
+	apply func(c *model.Config, value string) error
+}
+
+var settings = []setting{
+	{key: "APP_ENV", usage: "environment name, selecting the .env.<APP_ENV> file", apply: text(func(c *model.Config) *string { return &c.AppEnv })},
+	{key: "BIND_ADDRESS", def: "9090", usage: "port or host:port to listen on", apply: bindAddress},
+	{key: "INTERNAL_BIND_ADDRESS", def: "9091", usage: "port or host:port of the plaintext listener serving metrics and health probes, not to be exposed publicly; empty disables it", apply: internalBindAddress},
+
+	{key: "SERVER_READ_TIMEOUT", def: "5s", usage: "time allowed to read a whole request", apply: duration(func(c *model.Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{key: "SERVER_READ_HEADER_TIMEOUT", def: "5s", usage: "time allowed to read request headers", apply: duration(func(c *model.Config) *time.Duration { return &c.Server.ReadHeaderTimeout })},
+	{key: "SERVER_WRITE_TIMEOUT", def: "10s", usage: "time allowed to write a response", apply: duration(func(c *model.Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{key: "SERVER_IDLE_TIMEOUT", def: "120s", usage: "time keep-alive connections may stay idle", apply: duration(func(c *model.Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{key: "SERVER_MAX_HEADER_BYTES", def: strconv.Itoa(http.DefaultMaxHeaderBytes), usage: "maximum size of request headers", apply: positive(func(c *model.Config) *int { return &c.Server.MaxHeaderBytes })},
+	{key: "SERVER_HTTP2", def: "true", usage: "serve HTTP/2, with ALPN over TLS and h2c otherwise", apply: boolean(func(c *model.Config) *bool { return &c.Server.HTTP2 })},
+	{key: "TLS_CERT_FILE", usage: "PEM certificate to serve HTTPS with, reloaded when changed", apply: text(func(c *model.Config) *string { return &c.Server.TLS.CertFile })},
+	{key: "TLS_KEY_FILE", usage: "PEM private key of TLS_CERT_FILE", apply: text(func(c *model.Config) *string { return &c.Server.TLS.KeyFile })},
+	{key: "TLS_CLIENT_CA_FILE", usage: "PEM CAs client certificates must chain to, enabling mutual TLS", apply: text(func(c *model.Config) *string { return &c.Server.TLS.ClientCAFile })},
+	{key: "SHUTDOWN_DELAY", def: "0s", usage: "time requests keep being served once readiness reports the shutdown", apply: duration(func(c *model.Config) *time.Duration { return &c.ShutdownDelay })},
+	{key: "SHUTDOWN_TIMEOUT", def: "30s", usage: "time in-flight requests get to complete on shutdown", apply: duration(func(c *model.Config) *time.Duration { return &c.ShutdownTimeout })},
+	{key: "REQUEST_TIMEOUT", def: "5s", usage: "time handlers get per request, 0 to disable", apply: duration(func(c *model.Config) *time.Duration { return &c.RequestTimeout })},
+
+	{key: "DB_DRIVER", def: "memory", usage: "storage backend: memory, sqlite or postgres", apply: text(func(c *model.Config) *string { return &c.DBDriver })},
+	{key: "DB_DSN", usage: "SQLite file or Postgres connection string", apply: text(func(c *model.Config) *string { return &c.DBDSN })},
+	{key: "CLAIM_ORPHANED_TODOS", usage: "username the todos created before accounts existed are given to at startup", apply: text(func(c *model.Config) *string { return &c.ClaimOrphanedTodos })},
+
+	{key: "TOKEN_TTL", def: "24h", usage: "lifetime of issued access tokens", apply: duration(func(c *model.Config) *time.Duration { return &c.TokenTTL })},
+	{key: "JWT_ALGORITHM", def: "HS256", usage: "token signing algorithm: HS256 or RS256", apply: text(func(c *model.Config) *string { return &c.JWTAlgorithm })},
+	{key: "JWT_SECRET", usage: "HS256 signing secret", secret: true, apply: text(func(c *model.Config) *string { return &c.JWTSecret })},
+	{key: "JWT_PRIVATE_KEY_FILE", usage: "PEM RSA private key signing RS256 tokens", apply: file(func(c *model.Config) *[]byte { return &c.JWTPrivateKey })},
+	{key: "JWT_PUBLIC_KEY_FILE", usage: "PEM RSA public key verifying RS256 tokens", apply: file(func(c *model.Config) *[]byte { return &c.JWTPublicKey })},
+	{key: "JWT_ISSUER", usage: "iss claim of issued tokens, required on verified ones when set", apply: text(func(c *model.Config) *string { return &c.JWTIssuer })},
+	{key: "JWT_CLOCK_SKEW", def: "30s", usage: "clock skew tolerated when checking token times", apply: duration(func(c *model.Config) *time.Duration { return &c.JWTClockSkew })},
+
+	{key: "CORS_ALLOWED_ORIGINS", usage: "comma separated origins allowed cross-origin, * matching a label", apply: list(func(c *model.Config) *[]string { return &c.CORS.AllowedOrigins })},
+	{key: "CORS_ALLOWED_METHODS", def: "GET,POST,PUT,PATCH,DELETE", usage: "comma separated methods allowed cross-origin", apply: list(func(c *model.Config) *[]string { return &c.CORS.AllowedMethods })},
+	{key: "CORS_ALLOWED_HEADERS", def: "Accept,Authorization,Content-Type,X-Requested-With", usage: "comma separated request headers allowed cross-origin", apply: list(func(c *model.Config) *[]string { return &c.CORS.AllowedHeaders })},
+	{key: "CORS_EXPOSED_HEADERS", def: "X-Request-ID,RateLimit-Policy,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After", usage: "comma separated response headers exposed cross-origin", apply: list(func(c *model.Config) *[]string { return &c.CORS.ExposedHeaders })},
+	{key: "CORS_MAX_AGE", def: "10m", usage: "time browsers may cache preflight responses", apply: duration(func(c *model.Config) *time.Duration { return &c.CORS.MaxAge })},
+	{key: "CORS_ALLOW_CREDENTIALS", def: "false", usage: "allow cross-origin requests with credentials", apply: boolean(func(c *model.Config) *bool { return &c.CORS.AllowCredentials })},
+
+	{key: "TRUSTED_PROXIES", usage: "comma separated IPs or CIDRs whose X-Forwarded-For is trusted", apply: list(func(c *model.Config) *[]string { return &c.TrustedProxies })},
+	{key: "RATE_LIMIT", def: "120/1m", usage: "default limit per client as <requests>/<duration>, 0 requests to disable", apply: rateLimit},
+	{key: "RATE_LIMIT_BURST", def: "0", usage: "requests allowed at once, defaulting to the requests of RATE_LIMIT", apply: rateLimitBurst},
+	{key: "RATE_LIMIT_ROUTES", usage: "limits of routes as <route name>=<requests>/<duration>;...", pairs: true, apply: rateLimitRoutes},
+
+	{key: "LOG_LEVEL", def: "info", usage: "minimum log level: debug, info, warn or error", apply: logLevel},
+	{key: "LOG_FORMAT", def: "json", usage: "log format: json or text", apply: text(func(c *model.Config) *string { return &c.LogFormat })},
+
+	{key: "TRACING_EXPORTER", def: "none", usage: "span exporter: none, stdout or otlp", apply: text(func(c *model.Config) *string { return &c.Tracing.Exporter })},
+	{key: "TRACING_OTLP_ENDPOINT", usage: "host:port of the OTLP/HTTP collector", apply: text(func(c *model.Config) *string { return &c.Tracing.OTLPEndpoint })},
+	{key: "TRACING_OTLP_INSECURE", def: "false", usage: "export spans to the collector without TLS", apply: boolean(func(c *model.Config) *bool { return &c.Tracing.OTLPInsecure })},
+	{key: "TRACING_SAMPLE_RATIO", def: "1", usage: "share of new traces recorded, between 0 and 1", apply: sampleRatio},
+}
+
+func text(field func(*model.Config) *string) func(*model.Config, string) error {
+	return func(c *model.Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+// duration accepts time.ParseDuration values that are not negative.
+func duration(field func(*model.Config) *time.Duration) func(*model.Config, string) error {
+	return func(c *model.Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("want a duration such as 30s or 5m")
+		}
+		if d < 0 {
+			return errors.New("must not be negative")
+		}
+
+		*field(c) = d
+		return nil
+	}
+}
+
+func boolean(field func(*model.Config) *bool) func(*model.Config, string) error {
+	return func(c *model.Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("want true or false")
+		}
+
+		*field(c) = b
+		return nil
+	}
+}
+
+func positive(field func(*model.Config) *int) func(*model.Config, string) error {
+	return func(c *model.Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return errors.New("want a positive number")
+		}
+
+		*field(c) = n
+		return nil
+	}
+}
+
+// list reads comma separated values; an empty value is an empty list.
+func list(field func(*model.Config) *[]string) func(*model.Config, string) error {
+	return func(c *model.Config, value string) error {
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+
+		*field(c) = values
+		return nil
+	}
+}
+
+// file reads the content of the file at the given path, if any.
+func file(field func(*model.Config) *[]byte) func(*model.Config, string) error {
+	return func(c *model.Config, path string) error {
+		if path == "" {
+			*field(c) = nil
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		*field(c) = content
+		return nil
+	}
+}
+
+func bindAddress(c *model.Config, value string) error {
+	if value == "" {
+		return errors.New("must not be empty")
+	}
+
+	c.BindAddress = listenAddress(value)
+	return nil
+}
+
+func internalBindAddress(c *model.Config, value string) error {
+	if value != "" {
+		value = listenAddress(value)
+	}
+
+	c.InternalBindAddress = value
+	return nil
+}
+
+// listenAddress listens on every interface when only a port is given.
+func listenAddress(value string) string {
+	if !strings.Contains(value, ":") {
+		return ":" + value
+	}
+
+	return value
+}
+
+func rateLimit(c *model.Config, value string) error {
+	limit, err := parseRateLimit(value)
+	if err != nil {
+		return err
+	}
+
+	limit.Burst = c.RateLimit.Default.Burst
+	c.RateLimit.Default = limit
+	return nil
+}
+
+func rateLimitBurst(c *model.Config, value string) error {
+	burst, err := strconv.Atoi(value)
+	if err != nil || burst < 0 {
+		return errors.New("want a number of requests")
+	}
+
+	c.RateLimit.Default.Burst = burst
+	return nil
+}
+
+func rateLimitRoutes(c *model.Config, value string) error {
+	routes := map[string]model.RateLimit{}
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		name, limit, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("entry %q: want <route name>=<requests>/<duration>", entry)
+		}
+
+		var err error
+		if routes[strings.TrimSpace(name)], err = parseRateLimit(limit); err != nil {
+			return fmt.Errorf("entry %q: %w", entry, err)
+		}
+	}
+
+	c.RateLimit.Routes = routes
+	return nil
+}
+
+// parseRateLimit reads limits written as "<requests>/<duration>", such as
+// "120/1m". A zero number of requests disables the limit.
+func parseRateLimit(value string) (model.RateLimit, error) {
+	requests, per, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return model.RateLimit{}, errors.New("want <requests>/<duration>")
+	}
+
+	limit := model.RateLimit{}
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests < 0 {
+		return model.RateLimit{}, fmt.Errorf("invalid number of requests %q", requests)
+	}
+	if limit.Per, err = time.ParseDuration(per); err != nil || limit.Per <= 0 {
+		return model.RateLimit{}, fmt.Errorf("invalid duration %q", per)
+	}
+
+	return limit, nil
+}
+
+func logLevel(c *model.Config, value string) error {
+	if err := c.LogLevel.UnmarshalText([]byte(value)); err != nil {
+		return errors.New("want debug, info, warn or error")
+	}
+
+	return nil
+}
+
+func sampleRatio(c *model.Config, value string) error {
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return errors.New("want a number between 0 and 1")
+	}
+
+	c.Tracing.SampleRatio = ratio
+	return nil
+}
+
+// lookup returns the setting with the given key.
+func lookup(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+
+	return setting{}, false
+}
@@ -0,0 +1,25 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+)
+
+var ErrNotFound = errors.New("no api key found")
+
+func errNotFound(id int) error {
+	return fmt.Errorf("%w with id:%d", ErrNotFound, id)
+}
+
+// StatusCode maps an error returned by the apikey package to an HTTP status.
+// Shared errors fall back to apperr.StatusCode.
+func StatusCode(err error) int {
+	if errors.Is(err, ErrNotFound) {
+		return http.StatusNotFound
+	}
+
+	return apperr.StatusCode(err)
+}
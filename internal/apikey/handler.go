@@ -0,0 +1,82 @@
+package apikey
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/util"
+)
+
+type APIKeyHandler struct {
+	ks Service
+}
+
+type Handler interface {
+	CreateAPIKey(rw http.ResponseWriter, r *http.Request)
+	ListAPIKeys(rw http.ResponseWriter, r *http.Request)
+	RevokeAPIKey(rw http.ResponseWriter, r *http.Request)
+}
+
+func NewAPIKeyHandler(ks Service) Handler {
+	return &APIKeyHandler{
+		ks: ks,
+	}
+}
+
+func (kh *APIKeyHandler) CreateAPIKey(rw http.ResponseWriter, r *http.Request) {
+	kh.ks.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "CreateAPIKey")
+
+	request := model.NewAPIKey{}
+	if err := request.FromJSON(http.MaxBytesReader(rw, r.Body, apperr.MaxBodyBytes)); err != nil {
+		apperr.WriteError(r.Context(), rw, apperr.BodyError(err), StatusCode, kh.ks.Log)
+		return
+	}
+
+	key, err := kh.ks.Create(r.Context(), request)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, kh.ks.Log)
+		return
+	}
+
+	util.WriteResponse(rw, util.SetAndGetResponse(true, "API Key Created Successfully", key, http.StatusOK))
+	kh.ks.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "CreateAPIKey")
+}
+
+func (kh *APIKeyHandler) ListAPIKeys(rw http.ResponseWriter, r *http.Request) {
+	kh.ks.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "ListAPIKeys")
+
+	keys, err := kh.ks.List(r.Context())
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, kh.ks.Log)
+		return
+	}
+
+	util.WriteResponse(rw, util.SetAndGetResponse(true, "API Keys Fetched Successfully", keys, http.StatusOK))
+	kh.ks.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "ListAPIKeys")
+}
+
+func (kh *APIKeyHandler) RevokeAPIKey(rw http.ResponseWriter, r *http.Request) {
+	kh.ks.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "RevokeAPIKey")
+
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		kh.ks.Log(r.Context(), slog.LevelInfo, "unable to convert id", "id", vars["id"], "error", err)
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Unable to convert id : "+vars["id"], nil, http.StatusBadRequest))
+		return
+	}
+
+	key, err := kh.ks.Revoke(r.Context(), id)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, kh.ks.Log)
+		return
+	}
+
+	util.WriteResponse(rw, util.SetAndGetResponse(true, "API Key Revoked Successfully", key, http.StatusOK))
+	kh.ks.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "RevokeAPIKey")
+}
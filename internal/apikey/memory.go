@@ -0,0 +1,119 @@
+package apikey
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// Memory keeps API keys in a map until the process exits. Revoked keys are
+// kept so that their owners still see them listed. Every method may be
+// called concurrently.
+type Memory struct {
+	mu     sync.RWMutex
+	lastID int
+	Keys   map[int]*model.APIKey
+}
+
+type DB interface {
+	Create(ctx context.Context, k *model.APIKey) (*model.APIKey, error)
+	List(ctx context.Context, ownerID int) ([]*model.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*model.APIKey, error)
+	Revoke(ctx context.Context, ownerID int, id int, at time.Time) (*model.APIKey, error)
+}
+
+func NewDB() DB {
+	return &Memory{
+		Keys: make(map[int]*model.APIKey),
+	}
+}
+
+func (m *Memory) Create(ctx context.Context, key *model.APIKey) (*model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+
+	stored := copyKey(key)
+	stored.ID = m.lastID
+	m.Keys[stored.ID] = stored
+
+	return copyKey(stored), nil
+}
+
+// List returns the keys of ownerID, revoked ones included, ordered by ID.
+func (m *Memory) List(ctx context.Context, ownerID int) ([]*model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []*model.APIKey{}
+	for _, key := range m.Keys {
+		if key.OwnerID == ownerID {
+			keys = append(keys, copyKey(key))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	return keys, nil
+}
+
+func (m *Memory) GetByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.Keys {
+		if key.Prefix == prefix {
+			return copyKey(key), nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Revoke marks the key as revoked at the given time. Revoking an already
+// revoked key keeps the original time.
+func (m *Memory) Revoke(ctx context.Context, ownerID int, id int, at time.Time) (*model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, exists := m.Keys[id]
+	if !exists || key.OwnerID != ownerID {
+		return nil, errNotFound(id)
+	}
+
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+
+	return copyKey(key), nil
+}
+
+func copyKey(key *model.APIKey) *model.APIKey {
+	c := *key
+	c.Scopes = append([]string(nil), key.Scopes...)
+	if key.RevokedAt != nil {
+		revokedAt := *key.RevokedAt
+		c.RevokedAt = &revokedAt
+	}
+
+	return &c
+}
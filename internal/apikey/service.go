@@ -0,0 +1,218 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+const (
+	// KeyPrefix starts every API key so leaked keys are easy to recognise.
+	KeyPrefix = "tda_"
+
+	MaxNameLength = 100
+)
+
+// GrantableScopes are the scopes an API key can be created with. Keys created
+// without scopes get all of them.
+var GrantableScopes = []string{auth.ScopeTodosRead, auth.ScopeTodosWrite}
+
+// APIKeyService manages the API keys of the authenticated caller and resolves
+// keys presented by machine clients to the identity of their owner.
+//
+// Keys have the form tda_<prefix>_<secret> with hex encoded random parts. The
+// prefix is stored in clear to find the key; the whole key is only stored as
+// a SHA-256 hash, which is enough for 256 bits of random secret.
+type APIKeyService struct {
+	L   *slog.Logger
+	DB  DB
+	now func() time.Time
+}
+
+type Service interface {
+	Create(ctx context.Context, k model.NewAPIKey) (*model.CreatedAPIKey, error)
+	List(ctx context.Context) ([]*model.APIKey, error)
+	Revoke(ctx context.Context, id int) (*model.APIKey, error)
+	Verify(ctx context.Context, key string) (auth.Identity, error)
+	Log(ctx context.Context, level slog.Level, msg string, args ...interface{})
+}
+
+func NewAPIKeyService(l *slog.Logger, db DB) Service {
+	return APIKeyService{
+		L:   l,
+		DB:  db,
+		now: time.Now,
+	}
+}
+
+func (ks APIKeyService) Create(ctx context.Context, request model.NewAPIKey) (*model.CreatedAPIKey, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if err := validateNewAPIKey(request); err != nil {
+		return nil, err
+	}
+	if len(request.Scopes) == 0 {
+		request.Scopes = GrantableScopes
+	}
+
+	prefix, err := randomString(6)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	plain := KeyPrefix + prefix + "_" + secret
+
+	key, err := ks.DB.Create(ctx, &model.APIKey{
+		Name:      request.Name,
+		Prefix:    prefix,
+		Scopes:    dedupe(request.Scopes),
+		CreatedAt: ks.now().UTC().Truncate(time.Second),
+		OwnerID:   owner,
+		Hash:      hash(plain),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+func (ks APIKeyService) List(ctx context.Context) ([]*model.APIKey, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ks.DB.List(ctx, owner)
+}
+
+func (ks APIKeyService) Revoke(ctx context.Context, id int) (*model.APIKey, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ks.DB.Revoke(ctx, owner, id, ks.now().UTC().Truncate(time.Second))
+}
+
+// Verify resolves a presented key to the identity of its owner, restricted to
+// the scopes of the key. Unknown, malformed and revoked keys are all reported
+// as auth.ErrInvalidToken.
+func (ks APIKeyService) Verify(ctx context.Context, plain string) (auth.Identity, error) {
+	rest, ok := strings.CutPrefix(plain, KeyPrefix)
+	if !ok {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+	prefix, _, ok := strings.Cut(rest, "_")
+	if !ok {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+
+	key, err := ks.DB.GetByPrefix(ctx, prefix)
+	if errors.Is(err, ErrNotFound) {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return auth.Identity{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash(plain))) != 1 || key.RevokedAt != nil {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+
+	return auth.Identity{UserID: key.OwnerID, APIKeyID: key.ID, Scopes: key.Scopes}, nil
+}
+
+// Log is used by the key handlers; records are tagged with the request ID
+// of ctx by L.
+func (ks APIKeyService) Log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	ks.L.Log(ctx, level, msg, args...)
+}
+
+func validateNewAPIKey(request model.NewAPIKey) error {
+	errs := apperr.ValidationErrors{}
+
+	if request.Name == "" {
+		errs = append(errs, &apperr.ValidationError{Field: "name", Message: "must not be empty"})
+	} else if utf8.RuneCountInString(request.Name) > MaxNameLength {
+		errs = append(errs, &apperr.ValidationError{Field: "name", Message: "must be at most 100 characters"})
+	}
+
+	for _, scope := range request.Scopes {
+		if !isGrantable(scope) {
+			errs = append(errs, &apperr.ValidationError{Field: "scopes", Message: "must only contain " + strings.Join(GrantableScopes, ", ")})
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func isGrantable(scope string) bool {
+	for _, grantable := range GrantableScopes {
+		if scope == grantable {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dedupe(scopes []string) []string {
+	unique := []string{}
+	for _, scope := range scopes {
+		seen := false
+		for _, u := range unique {
+			seen = seen || u == scope
+		}
+		if !seen {
+			unique = append(unique, scope)
+		}
+	}
+
+	return unique
+}
+
+func randomString(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
+
+func hash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func ownerID(ctx context.Context) (int, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return 0, apperr.ErrUnauthenticated
+	}
+
+	return identity.UserID, nil
+}
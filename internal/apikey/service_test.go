@@ -0,0 +1,135 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/database"
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// ServiceSuite runs the API key service against every DB implementation.
+type ServiceSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	newDB   func(t *testing.T) DB
+	now     time.Time
+	service APIKeyService
+	ctx     context.Context
+}
+
+func TestMemoryServiceSuite(t *testing.T) {
+	suite.Run(t, &ServiceSuite{newDB: func(t *testing.T) DB {
+		return NewDB()
+	}})
+}
+
+func TestSQLiteServiceSuite(t *testing.T) {
+	suite.Run(t, &ServiceSuite{newDB: func(t *testing.T) DB {
+		db, err := database.Open(database.DriverSQLite, filepath.Join(t.TempDir(), "todo.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		require.NoError(t, database.Migrate(context.Background(), db, database.DriverSQLite))
+
+		return NewSQLStore(db, database.DriverSQLite)
+	}})
+}
+
+func (s *ServiceSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.service = APIKeyService{L: logging.Discard(), DB: s.newDB(s.T()), now: func() time.Time { return s.now }}
+	s.ctx = auth.WithIdentity(context.Background(), auth.Identity{UserID: 1})
+}
+
+func (s *ServiceSuite) TestServiceGivenWhenCreateIsCalled() {
+	s.T().Run("TestServiceGivenNameWithoutScopesWhenCreateIsCalledThenItShouldGrantEveryScopeAndOnlyStoreAHash", func(t *testing.T) {
+		created, err := s.service.Create(s.ctx, model.NewAPIKey{Name: " nightly cron "})
+		s.NoError(err)
+
+		s.True(strings.HasPrefix(created.Key, KeyPrefix+created.Prefix+"_"))
+		s.Equal("nightly cron", created.Name)
+		s.Equal(GrantableScopes, created.Scopes)
+		s.Equal(s.now, created.CreatedAt)
+		s.NotContains(created.Hash, created.Key)
+
+		stored, err := s.service.DB.GetByPrefix(context.Background(), created.Prefix)
+		s.NoError(err)
+		s.Equal(hash(created.Key), stored.Hash)
+	})
+
+	s.T().Run("TestServiceGivenInvalidRequestWhenCreateIsCalledThenItShouldReportEveryInvalidField", func(t *testing.T) {
+		_, err := s.service.Create(s.ctx, model.NewAPIKey{Name: "  ", Scopes: []string{auth.ScopeAPIKeys}})
+
+		s.ErrorIs(err, apperr.ErrValidation)
+		s.Len(apperr.ValidationDetails(err), 2)
+	})
+
+	s.T().Run("TestServiceGivenNoIdentityWhenCreateIsCalledThenItShouldReturnErrUnauthenticated", func(t *testing.T) {
+		_, err := s.service.Create(context.Background(), model.NewAPIKey{Name: "cron"})
+
+		s.ErrorIs(err, apperr.ErrUnauthenticated)
+	})
+}
+
+func (s *ServiceSuite) TestServiceGivenWhenVerifyIsCalled() {
+	readOnly, err := s.service.Create(s.ctx, model.NewAPIKey{Name: "reports", Scopes: []string{auth.ScopeTodosRead, auth.ScopeTodosRead}})
+	s.NoError(err)
+
+	s.T().Run("TestServiceGivenCreatedKeyWhenVerifyIsCalledThenItShouldReturnTheScopedIdentityOfTheOwner", func(t *testing.T) {
+		identity, err := s.service.Verify(context.Background(), readOnly.Key)
+
+		s.NoError(err)
+		s.Equal(auth.Identity{UserID: 1, APIKeyID: readOnly.ID, Scopes: []string{auth.ScopeTodosRead}}, identity)
+		s.True(identity.Allows(auth.ScopeTodosRead))
+		s.False(identity.Allows(auth.ScopeTodosWrite))
+	})
+
+	s.T().Run("TestServiceGivenWrongSecretWhenVerifyIsCalledThenItShouldReturnErrInvalidToken", func(t *testing.T) {
+		for _, key := range []string{KeyPrefix + readOnly.Prefix + "_wrong", KeyPrefix + "unknown_secret", readOnly.Prefix, ""} {
+			_, err := s.service.Verify(context.Background(), key)
+			s.ErrorIs(err, auth.ErrInvalidToken, key)
+		}
+	})
+
+	s.T().Run("TestServiceGivenRevokedKeyWhenVerifyIsCalledThenItShouldReturnErrInvalidToken", func(t *testing.T) {
+		revoked, err := s.service.Revoke(s.ctx, readOnly.ID)
+		s.NoError(err)
+		s.Equal(s.now, *revoked.RevokedAt)
+
+		_, err = s.service.Verify(context.Background(), readOnly.Key)
+		s.ErrorIs(err, auth.ErrInvalidToken)
+	})
+}
+
+func (s *ServiceSuite) TestServiceGivenWhenListAndRevokeAreCalled() {
+	first, err := s.service.Create(s.ctx, model.NewAPIKey{Name: "first"})
+	s.NoError(err)
+	_, err = s.service.Create(auth.WithIdentity(context.Background(), auth.Identity{UserID: 2}), model.NewAPIKey{Name: "someone else's"})
+	s.NoError(err)
+
+	s.T().Run("TestServiceGivenKeysOfSeveralOwnersWhenListIsCalledThenItShouldOnlyReturnTheCallersKeys", func(t *testing.T) {
+		keys, err := s.service.List(s.ctx)
+
+		s.NoError(err)
+		s.Equal([]*model.APIKey{first.APIKey}, keys)
+	})
+
+	s.T().Run("TestServiceGivenKeyOfAnotherOwnerWhenRevokeIsCalledThenItShouldReturnErrNotFound", func(t *testing.T) {
+		_, err := s.service.Revoke(s.ctx, first.ID+1)
+
+		s.ErrorIs(err, ErrNotFound)
+		s.Equal(http.StatusNotFound, StatusCode(err))
+	})
+}
@@ -0,0 +1,94 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/database"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+const apiKeyColumns = "id, owner_id, name, prefix, hash, scopes, created_at, revoked_at"
+
+// SQLStore is a DB implementation backed by database/sql. Scopes are stored
+// space separated in a single column.
+type SQLStore struct {
+	DB     *sql.DB
+	Driver string
+}
+
+func NewSQLStore(db *sql.DB, driver string) *SQLStore {
+	return &SQLStore{DB: db, Driver: driver}
+}
+
+func (s *SQLStore) Create(ctx context.Context, key *model.APIKey) (*model.APIKey, error) {
+	return scanKey(s.queryRow(ctx, "INSERT INTO api_keys (owner_id, name, prefix, hash, scopes, created_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING "+apiKeyColumns,
+		key.OwnerID, key.Name, key.Prefix, key.Hash, strings.Join(key.Scopes, " "), key.CreatedAt))
+}
+
+func (s *SQLStore) List(ctx context.Context, ownerID int) ([]*model.APIKey, error) {
+	rows, err := s.DB.QueryContext(ctx, database.Rebind(s.Driver, "SELECT "+apiKeyColumns+" FROM api_keys WHERE owner_id = ? ORDER BY id"), ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*model.APIKey{}
+	for rows.Next() {
+		key, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (s *SQLStore) GetByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	key, err := scanKey(s.queryRow(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE prefix = ?", prefix))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return key, err
+}
+
+func (s *SQLStore) Revoke(ctx context.Context, ownerID int, id int, at time.Time) (*model.APIKey, error) {
+	key, err := scanKey(s.queryRow(ctx, "UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND owner_id = ? RETURNING "+apiKeyColumns,
+		at, id, ownerID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound(id)
+	}
+
+	return key, err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanKey(row rowScanner) (*model.APIKey, error) {
+	key := &model.APIKey{}
+	var scopes string
+	var revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.OwnerID, &key.Name, &key.Prefix, &key.Hash, &scopes, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	key.CreatedAt = key.CreatedAt.UTC()
+	if revokedAt.Valid {
+		at := revokedAt.Time.UTC()
+		key.RevokedAt = &at
+	}
+
+	return key, nil
+}
+
+func (s *SQLStore) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.DB.QueryRowContext(ctx, database.Rebind(s.Driver, query), args...)
+}
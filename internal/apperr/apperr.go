@@ -0,0 +1,141 @@
+// Package apperr holds the errors shared by the domain packages and maps them
+// to HTTP statuses. Domain packages add sentinels of their own, such as
+// todo.ErrNotFound, and fall back to StatusCode for the others.
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/util"
+)
+
+// MaxBodyBytes caps the size of every request body.
+const MaxBodyBytes = 64 << 10
+
+// Sentinel errors shared by the domain packages. Callers should match them
+// with errors.Is since they are usually wrapped with details.
+var (
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+
+	ErrUnauthenticated = errors.New("Authentication Required")
+
+	ErrInvalidJSON     = errors.New("Invalid JSON Data")
+	ErrPayloadTooLarge = errors.New("Request Body Too Large")
+)
+
+// ValidationError describes a single invalid field. It matches ErrValidation.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ValidationErrors collects every invalid field of a payload. It matches
+// ErrValidation.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, field := range e {
+		messages = append(messages, field.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ValidationDetails returns the field errors carried by err, if any.
+func ValidationDetails(err error) ValidationErrors {
+	var fields ValidationErrors
+	if errors.As(err, &fields) {
+		return fields
+	}
+
+	var field *ValidationError
+	if errors.As(err, &field) {
+		return ValidationErrors{field}
+	}
+
+	return nil
+}
+
+// BodyError converts an error raised while reading or decoding a request body
+// into the matching error, keeping the decoder message for the logs.
+func BodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("%w: limit is %d bytes", ErrPayloadTooLarge, tooLarge.Limit)
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &ValidationError{Field: strings.Trim(field, `"`), Message: "unknown field"}
+	}
+
+	return fmt.Errorf("%w: %s", ErrInvalidJSON, err.Error())
+}
+
+// StatusCode maps the errors of this package and context errors to an HTTP
+// status. Any other error is a server failure.
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrInvalidJSON):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrPayloadTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// LogFunc logs a record in the context of a request, like the Log method of
+// the domain services.
+type LogFunc func(ctx context.Context, level slog.Level, msg string, args ...interface{})
+
+// WriteError logs err and answers with the HTTP status that status maps it to.
+// Unexpected errors are logged as errors and reported without their details;
+// validation errors list the invalid fields.
+func WriteError(ctx context.Context, rw http.ResponseWriter, err error, status func(error) int, log LogFunc) {
+	code := status(err)
+	level := slog.LevelInfo
+	if code >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	log(ctx, level, "request failed", "status", code, "error", err)
+
+	message := err.Error()
+	if code == http.StatusInternalServerError {
+		message = http.StatusText(code)
+	}
+
+	var data interface{}
+	if details := ValidationDetails(err); details != nil {
+		data = details
+	}
+
+	util.WriteResponse(rw, util.SetAndGetResponse(false, message, data, code))
+}
@@ -0,0 +1,95 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AppErrSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestAppErrSuite(t *testing.T) {
+	suite.Run(t, new(AppErrSuite))
+}
+
+func (s *AppErrSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+}
+
+func (s *AppErrSuite) TestAppErrGivenWhenStatusCodeIsCalled() {
+	cases := map[string]struct {
+		err      error
+		expected int
+	}{
+		"Unauthenticated":   {ErrUnauthenticated, http.StatusUnauthorized},
+		"WrappedConflict":   {fmt.Errorf("todo 1 was modified: %w", ErrConflict), http.StatusConflict},
+		"ValidationError":   {&ValidationError{Field: "value", Message: "must not be empty"}, http.StatusUnprocessableEntity},
+		"ValidationErrors":  {ValidationErrors{{Field: "value", Message: "must not be empty"}}, http.StatusUnprocessableEntity},
+		"InvalidJSON":       {fmt.Errorf("%w: unexpected EOF", ErrInvalidJSON), http.StatusBadRequest},
+		"PayloadTooLarge":   {ErrPayloadTooLarge, http.StatusRequestEntityTooLarge},
+		"DeadlineExceeded":  {context.DeadlineExceeded, http.StatusGatewayTimeout},
+		"UnexpectedFailure": {errors.New("database is locked"), http.StatusInternalServerError},
+	}
+
+	for name, c := range cases {
+		s.T().Run("TestAppErrGiven"+name+"WhenStatusCodeIsCalledThenItShouldReturn"+http.StatusText(c.expected), func(t *testing.T) {
+			s.Equal(c.expected, StatusCode(c.err))
+		})
+	}
+}
+
+func (s *AppErrSuite) TestAppErrGivenWhenBodyErrorIsCalled() {
+	s.T().Run("TestAppErrGivenOversizedBodyWhenBodyErrorIsCalledThenItShouldReturnErrPayloadTooLarge", func(t *testing.T) {
+		err := BodyError(&http.MaxBytesError{Limit: MaxBodyBytes})
+
+		s.ErrorIs(err, ErrPayloadTooLarge)
+		s.EqualError(err, "Request Body Too Large: limit is 65536 bytes")
+	})
+
+	s.T().Run("TestAppErrGivenUnknownFieldWhenBodyErrorIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		err := BodyError(errors.New(`json: unknown field "colour"`))
+
+		s.Equal(ValidationErrors{{Field: "colour", Message: "unknown field"}}, ValidationDetails(err))
+	})
+
+	s.T().Run("TestAppErrGivenMalformedJSONWhenBodyErrorIsCalledThenItShouldReturnErrInvalidJSON", func(t *testing.T) {
+		s.ErrorIs(BodyError(errors.New("unexpected EOF")), ErrInvalidJSON)
+	})
+}
+
+func (s *AppErrSuite) TestAppErrGivenWhenWriteErrorIsCalled() {
+	write := func(err error) (*httptest.ResponseRecorder, slog.Level) {
+		var logged slog.Level
+		w := httptest.NewRecorder()
+		WriteError(context.Background(), w, err, StatusCode, func(_ context.Context, level slog.Level, _ string, _ ...interface{}) {
+			logged = level
+		})
+		return w, logged
+	}
+
+	s.T().Run("TestAppErrGivenValidationErrorWhenWriteErrorIsCalledThenTheFieldsShouldBeReturned", func(t *testing.T) {
+		w, level := write(ValidationErrors{{Field: "value", Message: "must not be empty"}})
+
+		s.Equal(http.StatusUnprocessableEntity, w.Code)
+		s.Equal(slog.LevelInfo, level)
+		s.JSONEq(`{"success":false,"message":"value: must not be empty","code":422,"data":[{"field":"value","message":"must not be empty"}]}`, w.Body.String())
+	})
+
+	s.T().Run("TestAppErrGivenUnexpectedFailureWhenWriteErrorIsCalledThenItsDetailsShouldBeHiddenAndLoggedAsAnError", func(t *testing.T) {
+		w, level := write(errors.New("database is locked"))
+
+		s.Equal(http.StatusInternalServerError, w.Code)
+		s.Equal(slog.LevelError, level)
+		s.NotContains(w.Body.String(), "database is locked")
+	})
+}
@@ -0,0 +1,70 @@
+// Package auth carries the identity of the caller through request contexts
+// and issues and verifies the access tokens proving that identity.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+var ErrInvalidToken = errors.New("Invalid Or Expired Token")
+
+// Scopes limit what an API key may do. Users logged in with a token are not
+// limited by scopes.
+const (
+	ScopeTodosRead  = "todos:read"
+	ScopeTodosWrite = "todos:write"
+	// ScopeAPIKeys is never granted to API keys so that a key cannot mint
+	// or revoke other keys.
+	ScopeAPIKeys = "api_keys"
+)
+
+// Identity is the authenticated caller of a request. APIKeyID is set when the
+// caller used an API key, whose Scopes then restrict the identity.
+type Identity struct {
+	UserID   int
+	APIKeyID int
+	Scopes   []string
+}
+
+// Allows reports whether the identity may act within scope.
+func (i Identity) Allows(scope string) bool {
+	if i.APIKeyID == 0 || scope == "" {
+		return true
+	}
+
+	for _, granted := range i.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Verifier resolves a credential presented by a client to its identity.
+// Credentials that are not valid are reported as ErrInvalidToken.
+type Verifier interface {
+	Verify(ctx context.Context, token string) (Identity, error)
+}
+
+// Tokens issues access tokens for users and resolves them back to identities.
+type Tokens interface {
+	Verifier
+	Issue(user *model.User) (model.Token, error)
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying identity.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity stored in ctx by WithIdentity.
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
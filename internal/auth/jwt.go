@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+
+	// MinSecretLength is the shortest HS256 secret accepted; RFC 7518 asks for
+	// a key at least as long as the hash output.
+	MinSecretLength = 32
+)
+
+var ErrSigningUnavailable = errors.New("no JWT signing key configured")
+
+// JWT is a Tokens implementation issuing and verifying signed JSON Web Tokens.
+// The subject claim carries the user ID. Expiry, not-before and issued-at are
+// checked with ClockSkew of leeway to tolerate clocks that drift apart.
+type JWT struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	issuer    string
+	ttl       time.Duration
+	skew      time.Duration
+	now       func() time.Time
+}
+
+// NewJWT builds a JWT from the JWT* and Token* fields of config. RS256 only
+// needs a public key to verify tokens; without a private key Issue fails, which
+// suits deployments where another service logs users in.
+func NewJWT(config model.Config) (*JWT, error) {
+	j := &JWT{
+		issuer: config.JWTIssuer,
+		ttl:    config.TokenTTL,
+		skew:   config.JWTClockSkew,
+		now:    time.Now,
+	}
+
+	switch config.JWTAlgorithm {
+	case AlgorithmHS256, "":
+		if len(config.JWTSecret) < MinSecretLength {
+			return nil, fmt.Errorf("JWT secret must be at least %d bytes long", MinSecretLength)
+		}
+		j.method = jwt.SigningMethodHS256
+		j.signKey = []byte(config.JWTSecret)
+		j.verifyKey = j.signKey
+	case AlgorithmRS256:
+		j.method = jwt.SigningMethodRS256
+		if len(config.JWTPrivateKey) > 0 {
+			key, err := jwt.ParseRSAPrivateKeyFromPEM(config.JWTPrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("parse JWT private key: %w", err)
+			}
+			j.signKey = key
+			j.verifyKey = &key.PublicKey
+		}
+		if len(config.JWTPublicKey) > 0 {
+			key, err := jwt.ParseRSAPublicKeyFromPEM(config.JWTPublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("parse JWT public key: %w", err)
+			}
+			j.verifyKey = key
+		}
+		if j.verifyKey == nil {
+			return nil, errors.New("RS256 needs a JWT private or public key")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", config.JWTAlgorithm)
+	}
+
+	return j, nil
+}
+
+func (j *JWT) Issue(user *model.User) (model.Token, error) {
+	if j.signKey == nil {
+		return model.Token{}, ErrSigningUnavailable
+	}
+
+	now := j.now()
+	expiresAt := now.Add(j.ttl)
+	claims := jwt.RegisteredClaims{
+		Issuer:    j.issuer,
+		Subject:   strconv.Itoa(user.ID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	signed, err := jwt.NewWithClaims(j.method, claims).SignedString(j.signKey)
+	if err != nil {
+		return model.Token{}, err
+	}
+
+	return model.Token{
+		AccessToken: signed,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt.Truncate(time.Second).UTC(),
+	}, nil
+}
+
+// Verify checks the signature and the time based claims of token. Only the
+// configured algorithm is accepted, so an HS256 token signed with an RSA
+// public key cannot pass for an RS256 one.
+func (j *JWT) Verify(_ context.Context, token string) (Identity, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{j.method.Alg()}),
+		jwt.WithLeeway(j.skew),
+		jwt.WithTimeFunc(j.now),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if j.issuer != "" {
+		options = append(options, jwt.WithIssuer(j.issuer))
+	}
+
+	claims := jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return j.verifyKey, nil
+	}, options...)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil || userID <= 0 {
+		return Identity{}, fmt.Errorf("%w: invalid subject", ErrInvalidToken)
+	}
+
+	return Identity{UserID: userID}, nil
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+type JWTSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	now    time.Time
+	config model.Config
+}
+
+func TestJWTSuite(t *testing.T) {
+	suite.Run(t, new(JWTSuite))
+}
+
+func (s *JWTSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.config = model.Config{
+		TokenTTL:     time.Hour,
+		JWTAlgorithm: AlgorithmHS256,
+		JWTSecret:    "jwt-test-secret-of-at-least-32-bytes",
+		JWTIssuer:    "todo-app-api",
+		JWTClockSkew: 30 * time.Second,
+	}
+}
+
+func (s *JWTSuite) newJWT(config model.Config) *JWT {
+	j, err := NewJWT(config)
+	s.NoError(err)
+	j.now = func() time.Time { return s.now }
+	return j
+}
+
+func (s *JWTSuite) TestJWTGivenHS256() {
+	s.T().Run("TestJWTGivenIssuedTokenWhenItIsVerifiedThenItShouldReturnTheIdentity", func(t *testing.T) {
+		j := s.newJWT(s.config)
+
+		token, err := j.Issue(&model.User{ID: 7})
+		s.NoError(err)
+		s.Equal("Bearer", token.TokenType)
+		s.Equal(s.now.Add(time.Hour), token.ExpiresAt)
+
+		identity, err := j.Verify(context.Background(), token.AccessToken)
+		s.NoError(err)
+		s.Equal(Identity{UserID: 7}, identity)
+	})
+
+	s.T().Run("TestJWTGivenExpiredTokenWithinClockSkewWhenItIsVerifiedThenItShouldBeAccepted", func(t *testing.T) {
+		j := s.newJWT(s.config)
+		token, err := j.Issue(&model.User{ID: 7})
+		s.NoError(err)
+
+		j.now = func() time.Time { return s.now.Add(time.Hour + 20*time.Second) }
+		_, err = j.Verify(context.Background(), token.AccessToken)
+		s.NoError(err)
+	})
+
+	s.T().Run("TestJWTGivenExpiredTokenBeyondClockSkewWhenItIsVerifiedThenItShouldReturnErrInvalidToken", func(t *testing.T) {
+		j := s.newJWT(s.config)
+		token, err := j.Issue(&model.User{ID: 7})
+		s.NoError(err)
+
+		j.now = func() time.Time { return s.now.Add(time.Hour + time.Minute) }
+		_, err = j.Verify(context.Background(), token.AccessToken)
+		s.ErrorIs(err, ErrInvalidToken)
+	})
+
+	s.T().Run("TestJWTGivenTokenIssuedInTheFutureBeyondClockSkewWhenItIsVerifiedThenItShouldReturnErrInvalidToken", func(t *testing.T) {
+		j := s.newJWT(s.config)
+		token, err := j.Issue(&model.User{ID: 7})
+		s.NoError(err)
+
+		j.now = func() time.Time { return s.now.Add(-time.Minute) }
+		_, err = j.Verify(context.Background(), token.AccessToken)
+		s.ErrorIs(err, ErrInvalidToken)
+	})
+
+	s.T().Run("TestJWTGivenTokenOfAnotherIssuerWhenItIsVerifiedThenItShouldReturnErrInvalidToken", func(t *testing.T) {
+		config := s.config
+		config.JWTIssuer = "someone-else"
+		token, err := s.newJWT(config).Issue(&model.User{ID: 7})
+		s.NoError(err)
+
+		_, err = s.newJWT(s.config).Verify(context.Background(), token.AccessToken)
+		s.ErrorIs(err, ErrInvalidToken)
+	})
+
+	s.T().Run("TestJWTGivenUnsignedTokenWhenItIsVerifiedThenItShouldReturnErrInvalidToken", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{
+			Subject:   "7",
+			ExpiresAt: jwt.NewNumericDate(s.now.Add(time.Hour)),
+		}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		s.NoError(err)
+
+		_, err = s.newJWT(s.config).Verify(context.Background(), token)
+		s.ErrorIs(err, ErrInvalidToken)
+	})
+
+	s.T().Run("TestJWTGivenShortSecretWhenItIsBuiltThenItShouldReturnAnError", func(t *testing.T) {
+		config := s.config
+		config.JWTSecret = "short"
+
+		_, err := NewJWT(config)
+		s.Error(err)
+	})
+}
+
+func (s *JWTSuite) TestJWTGivenRS256() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.NoError(err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	s.NoError(err)
+
+	config := s.config
+	config.JWTAlgorithm = AlgorithmRS256
+	config.JWTSecret = ""
+	config.JWTPrivateKey = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	verifyOnly := config
+	verifyOnly.JWTPrivateKey = nil
+	verifyOnly.JWTPublicKey = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+
+	s.T().Run("TestJWTGivenTokenSignedWithThePrivateKeyWhenItIsVerifiedWithThePublicKeyThenItShouldReturnTheIdentity", func(t *testing.T) {
+		token, err := s.newJWT(config).Issue(&model.User{ID: 7})
+		s.NoError(err)
+
+		identity, err := s.newJWT(verifyOnly).Verify(context.Background(), token.AccessToken)
+		s.NoError(err)
+		s.Equal(Identity{UserID: 7}, identity)
+	})
+
+	s.T().Run("TestJWTGivenOnlyAPublicKeyWhenATokenIsIssuedThenItShouldReturnErrSigningUnavailable", func(t *testing.T) {
+		_, err := s.newJWT(verifyOnly).Issue(&model.User{ID: 7})
+
+		s.ErrorIs(err, ErrSigningUnavailable)
+	})
+
+	s.T().Run("TestJWTGivenHS256TokenSignedWithThePublicKeyWhenItIsVerifiedThenItShouldReturnErrInvalidToken", func(t *testing.T) {
+		forged := s.config
+		forged.JWTSecret = string(verifyOnly.JWTPublicKey)
+		token, err := s.newJWT(forged).Issue(&model.User{ID: 7})
+		s.NoError(err)
+
+		_, err = s.newJWT(verifyOnly).Verify(context.Background(), token.AccessToken)
+		s.ErrorIs(err, ErrInvalidToken)
+	})
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// migrationLockID is an arbitrary key for the Postgres advisory lock that keeps
+// concurrently booting instances from applying the same migration twice.
+const migrationLockID = 7261626
+
+//go:embed migrations
+var migrations embed.FS
+
+// Open opens a connection pool for driver and verifies it is reachable.
+func Open(driver string, dsn string) (*sql.DB, error) {
+	if driver == DriverSQLite {
+		dsn = sqliteDSN(dsn)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// sqlitePragmas are set on every SQLite connection: writers wait for locks
+// instead of failing at once, and readers do not block writers.
+var sqlitePragmas = []string{"busy_timeout(5000)", "journal_mode(WAL)"}
+
+// sqliteDSN turns a SQLite file name, or URI with a query string of its own,
+// into a URI setting sqlitePragmas unless it already sets them itself.
+func sqliteDSN(dsn string) string {
+	if !strings.HasPrefix(dsn, "file:") {
+		dsn = "file:" + dsn
+	}
+
+	for _, pragma := range sqlitePragmas {
+		name, _, _ := strings.Cut(pragma, "(")
+		if strings.Contains(dsn, "_pragma="+name) {
+			continue
+		}
+
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "_pragma=" + pragma
+	}
+
+	return dsn
+}
+
+// Migrate applies every embedded migration for driver that is not yet recorded
+// in the schema_migrations table. Migrations are plain SQL files named
+// <version>_<description>.sql and each one runs in its own transaction.
+func Migrate(ctx context.Context, db *sql.DB, driver string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if driver == DriverPostgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+	}
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER   PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return fmt.Errorf("no migrations for driver %q: %w", driver, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		version, err := strconv.Atoi(strings.SplitN(entry.Name(), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("invalid migration file name %s: %w", entry.Name(), err)
+		}
+
+		var applied int
+		err = conn.QueryRowContext(ctx, Rebind(driver, "SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		query, err := fs.ReadFile(migrations, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s failed: %w", entry.Name(), err)
+		}
+		if _, err := tx.ExecContext(ctx, Rebind(driver, "INSERT INTO schema_migrations (version) VALUES (?)"), version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rebind rewrites the ? placeholders of query into the positional $N form
+// Postgres expects. Queries for other drivers are returned unchanged.
+func Rebind(driver string, query string) string {
+	if driver != DriverPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
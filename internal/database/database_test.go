@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type DatabaseSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestDatabaseSuite(t *testing.T) {
+	suite.Run(t, new(DatabaseSuite))
+}
+
+func (s *DatabaseSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+}
+
+func (s *DatabaseSuite) TestDatabaseGivenWhenRebindIsCalled() {
+	s.T().Run("TestDatabaseGivenPostgresDriverWhenRebindIsCalledThenItShouldReturnPositionalPlaceholders", func(t *testing.T) {
+		s.Equal("UPDATE todos SET value = $1 WHERE id = $2", Rebind(DriverPostgres, "UPDATE todos SET value = ? WHERE id = ?"))
+	})
+
+	s.T().Run("TestDatabaseGivenSQLiteDriverWhenRebindIsCalledThenItShouldReturnTheQueryUnchanged", func(t *testing.T) {
+		s.Equal("UPDATE todos SET value = ? WHERE id = ?", Rebind(DriverSQLite, "UPDATE todos SET value = ? WHERE id = ?"))
+	})
+}
+
+func (s *DatabaseSuite) TestDatabaseGivenWhenASQLiteDSNIsBuilt() {
+	s.T().Run("TestDatabaseGivenEveryFormOfDSNWhenSQLiteDSNIsCalledThenItShouldHaveOneQueryStringWithThePragmas", func(t *testing.T) {
+		for dsn, expected := range map[string]string{
+			"/app/data/todo.db":                       "file:/app/data/todo.db?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+			"todo.db?cache=shared":                    "file:todo.db?cache=shared&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+			"file:todo.db?_pragma=busy_timeout(100)":  "file:todo.db?_pragma=busy_timeout(100)&_pragma=journal_mode(WAL)",
+			"file:todo.db?mode=rwc&_txlock=immediate": "file:todo.db?mode=rwc&_txlock=immediate&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+		} {
+			s.Equal(expected, sqliteDSN(dsn), dsn)
+		}
+	})
+
+	s.T().Run("TestDatabaseGivenDSNWithAQueryStringWhenOpenIsCalledThenItShouldConnect", func(t *testing.T) {
+		db, err := Open(DriverSQLite, filepath.Join(t.TempDir(), "todo.db")+"?_txlock=immediate")
+		s.NoError(err)
+		defer db.Close()
+
+		var mode string
+		s.NoError(db.QueryRow("PRAGMA journal_mode").Scan(&mode))
+		s.Equal("wal", mode)
+	})
+}
+
+func (s *DatabaseSuite) TestDatabaseGivenWhenMigrateIsCalled() {
+	s.T().Run("TestDatabaseGivenFreshDatabaseWhenMigrateIsCalledTwiceThenEveryMigrationShouldBeAppliedOnce", func(t *testing.T) {
+		db, err := Open(DriverSQLite, filepath.Join(t.TempDir(), "todo.db"))
+		s.NoError(err)
+		defer db.Close()
+
+		s.NoError(Migrate(context.Background(), db, DriverSQLite))
+		s.NoError(Migrate(context.Background(), db, DriverSQLite))
+
+		entries, err := migrations.ReadDir("migrations/" + DriverSQLite)
+		s.NoError(err)
+
+		var applied int
+		s.NoError(db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&applied))
+		s.Equal(len(entries), applied)
+
+		_, err = db.Exec("INSERT INTO todos (value, status) VALUES ('buy some milk', 'open')")
+		s.NoError(err)
+	})
+
+	s.T().Run("TestDatabaseGivenUnknownDriverWhenMigrateIsCalledThenItShouldReturnAnError", func(t *testing.T) {
+		db, err := Open(DriverSQLite, filepath.Join(t.TempDir(), "todo.db"))
+		s.NoError(err)
+		defer db.Close()
+
+		s.Error(Migrate(context.Background(), db, "mysql"))
+	})
+}
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS todos (
+	id     BIGSERIAL PRIMARY KEY,
+	value  TEXT      NOT NULL,
+	marked INTEGER   NOT NULL DEFAULT 0
+);
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS todos_value_id_idx ON todos (value COLLATE "C", id);
+CREATE INDEX IF NOT EXISTS todos_marked_id_idx ON todos (marked, id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS users (
+	id            BIGSERIAL PRIMARY KEY,
+	username      TEXT      NOT NULL UNIQUE,
+	password_hash TEXT      NOT NULL
+);
+
+-- Todos created before accounts existed keep owner 0 and belong to nobody
+-- until CLAIM_ORPHANED_TODOS gives them to a user at startup.
+ALTER TABLE todos ADD COLUMN owner_id BIGINT NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS todos_value_id_idx;
+DROP INDEX IF EXISTS todos_marked_id_idx;
+CREATE INDEX todos_owner_id_idx ON todos (owner_id, id);
+CREATE INDEX todos_owner_value_id_idx ON todos (owner_id, value COLLATE "C", id);
+CREATE INDEX todos_owner_marked_id_idx ON todos (owner_id, marked, id);
@@ -0,0 +1,13 @@
+-- Todos created before this migration get its time as creation and update
+-- time.
+ALTER TABLE todos ADD COLUMN description  TEXT        NOT NULL DEFAULT '';
+ALTER TABLE todos ADD COLUMN due_date     TIMESTAMPTZ;
+ALTER TABLE todos ADD COLUMN priority     TEXT        NOT NULL DEFAULT '';
+ALTER TABLE todos ADD COLUMN created_at   TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE todos ADD COLUMN updated_at   TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE todos ADD COLUMN completed_at TIMESTAMPTZ;
+
+UPDATE todos SET completed_at = updated_at WHERE marked = 1;
+
+CREATE INDEX todos_owner_priority_id_idx ON todos (owner_id, priority, id);
+CREATE INDEX todos_owner_due_date_idx ON todos (owner_id, due_date);
@@ -0,0 +1,8 @@
+-- The status workflow replaces the marked flag: marked todos are done and the
+-- others open. Marked is now derived from the status.
+ALTER TABLE todos ADD COLUMN status TEXT NOT NULL DEFAULT 'open';
+UPDATE todos SET status = 'done' WHERE marked = 1;
+
+DROP INDEX IF EXISTS todos_owner_marked_id_idx;
+ALTER TABLE todos DROP COLUMN marked;
+CREATE INDEX todos_owner_status_id_idx ON todos (owner_id, status, id);
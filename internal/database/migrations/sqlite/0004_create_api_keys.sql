@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+	id         INTEGER  PRIMARY KEY AUTOINCREMENT,
+	owner_id   INTEGER  NOT NULL,
+	name       TEXT     NOT NULL,
+	prefix     TEXT     NOT NULL UNIQUE,
+	hash       TEXT     NOT NULL,
+	scopes     TEXT     NOT NULL,
+	created_at DATETIME NOT NULL,
+	revoked_at DATETIME
+);
+
+CREATE INDEX api_keys_owner_id_idx ON api_keys (owner_id, id);
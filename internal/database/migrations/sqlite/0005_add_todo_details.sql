@@ -0,0 +1,14 @@
+-- SQLite can not add columns defaulting to the current time, so todos created
+-- before this migration get its time as creation and update time.
+ALTER TABLE todos ADD COLUMN description  TEXT     NOT NULL DEFAULT '';
+ALTER TABLE todos ADD COLUMN due_date     DATETIME;
+ALTER TABLE todos ADD COLUMN priority     TEXT     NOT NULL DEFAULT '';
+ALTER TABLE todos ADD COLUMN created_at   DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE todos ADD COLUMN updated_at   DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE todos ADD COLUMN completed_at DATETIME;
+
+UPDATE todos SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP;
+UPDATE todos SET completed_at = CURRENT_TIMESTAMP WHERE marked = 1;
+
+CREATE INDEX todos_owner_priority_id_idx ON todos (owner_id, priority, id);
+CREATE INDEX todos_owner_due_date_idx ON todos (owner_id, due_date);
@@ -0,0 +1,3 @@
+-- version counts the writes of a todo. Updates are conditional on the version
+-- they were computed from so that concurrent ones can not overwrite each other.
+ALTER TABLE todos ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,72 @@
+// Package logging builds the structured loggers of the application and
+// carries request scoped attributes, such as the request ID, through contexts
+// so every log line written with a *Context method includes them.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New returns a logger writing records of at least level to w in format.
+func New(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(w, options)
+	case FormatText:
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return slog.New(ContextHandler{Handler: handler}), nil
+}
+
+// Discard returns a logger dropping every record, for tests.
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the current request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ContextHandler adds the request ID found in the context of a record to it.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func (h ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	return ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
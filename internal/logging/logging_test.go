@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerGivenContextWithRequestIDWhenARecordIsLoggedThenItShouldIncludeTheRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(buf, slog.LevelInfo, FormatJSON)
+	require.NoError(t, err)
+
+	logger.With("component", "test").InfoContext(WithRequestID(context.Background(), "abc"), "hello", "answer", 42)
+
+	line := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "abc", line["request_id"])
+	require.Equal(t, "test", line["component"])
+	require.Equal(t, float64(42), line["answer"])
+	require.Equal(t, "INFO", line["level"])
+}
+
+func TestLoggerGivenLevelWhenALowerRecordIsLoggedThenItShouldBeDropped(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(buf, slog.LevelWarn, FormatText)
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	require.Empty(t, buf.String())
+
+	logger.Warn("kept")
+	require.Contains(t, buf.String(), "kept")
+}
+
+func TestNewGivenUnknownFormatWhenItIsCalledThenItShouldReturnAnError(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, slog.LevelInfo, "xml")
+
+	require.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+)
+
+// instrumentedDB times every call made to the wrapped todo.DB.
+type instrumentedDB struct {
+	db todo.DB
+	m  *Metrics
+}
+
+// InstrumentDB returns db with the duration of each of its methods recorded.
+func (m *Metrics) InstrumentDB(db todo.DB) todo.DB {
+	return &instrumentedDB{db: db, m: m}
+}
+
+func (i *instrumentedDB) Get(ctx context.Context, ownerID int, id int) (t *model.Todo, err error) {
+	defer func(start time.Time) { i.m.observe("get", start, err) }(time.Now())
+	return i.db.Get(ctx, ownerID, id)
+}
+
+func (i *instrumentedDB) List(ctx context.Context, ownerID int, opts model.ListOptions) (todos []*model.Todo, page model.Pagination, err error) {
+	defer func(start time.Time) { i.m.observe("list", start, err) }(time.Now())
+	return i.db.List(ctx, ownerID, opts)
+}
+
+func (i *instrumentedDB) Create(ctx context.Context, t *model.Todo) (created *model.Todo, err error) {
+	defer func(start time.Time) { i.m.observe("create", start, err) }(time.Now())
+	return i.db.Create(ctx, t)
+}
+
+func (i *instrumentedDB) Mark(ctx context.Context, t *model.Todo, version int) (marked *model.Todo, err error) {
+	defer func(start time.Time) { i.m.observe("mark", start, err) }(time.Now())
+	return i.db.Mark(ctx, t, version)
+}
+
+func (i *instrumentedDB) Delete(ctx context.Context, ownerID int, id int) (err error) {
+	defer func(start time.Time) { i.m.observe("delete", start, err) }(time.Now())
+	return i.db.Delete(ctx, ownerID, id)
+}
+
+func (i *instrumentedDB) Count(ctx context.Context) (count int, err error) {
+	defer func(start time.Time) { i.m.observe("count", start, err) }(time.Now())
+	return i.db.Count(ctx)
+}
+
+func (i *instrumentedDB) Ping(ctx context.Context) (err error) {
+	defer func(start time.Time) { i.m.observe("ping", start, err) }(time.Now())
+	return i.db.Ping(ctx)
+}
@@ -0,0 +1,139 @@
+// Package metrics collects the Prometheus metrics of the application: HTTP
+// traffic per route, store operation timings and the number of todos.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+)
+
+const namespace = "todo_app"
+
+// countTimeout bounds the store query counting todos.
+const countTimeout = 5 * time.Second
+
+// countInterval is how long the number of todos is reused between scrapes,
+// sparing the store a full count on every one of them.
+const countInterval = time.Minute
+
+// Metrics owns a registry of its own so several servers, as in tests, do not
+// collide on the global one.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests   *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+	inFlight   *prometheus.GaugeVec
+	operations *prometheus.HistogramVec
+
+	now func() time.Time
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by route name, method and status code.",
+		}, []string{"route", "method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by route name and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests being served, by route name.",
+		}, []string{"route"}),
+		operations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "store_operation_duration_seconds",
+			Help:      "Time taken by todo store operations, by operation and outcome (ok, not_found or error).",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation", "outcome"}),
+		now: time.Now,
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.latency,
+		m.inFlight,
+		m.operations,
+	)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus text exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// InstrumentRoute counts, times and tracks in flight the requests of the route
+// called name.
+func (m *Metrics) InstrumentRoute(name string, next http.Handler) http.Handler {
+	labels := prometheus.Labels{"route": name}
+
+	handler := promhttp.InstrumentHandlerCounter(m.requests.MustCurryWith(labels), next)
+	handler = promhttp.InstrumentHandlerDuration(m.latency.MustCurryWith(labels), handler)
+	return promhttp.InstrumentHandlerInFlight(m.inFlight.With(labels), handler)
+}
+
+// CountTodos exposes the number of todos in db, counted at most once per
+// countInterval. The last count is kept when counting fails.
+func (m *Metrics) CountTodos(db todo.DB) {
+	var (
+		mu      sync.Mutex
+		count   float64
+		counted time.Time
+	)
+
+	m.Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "todos",
+		Help:      "Number of todos stored, of every owner.",
+	}, func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := m.now()
+		if !counted.IsZero() && now.Sub(counted) < countInterval {
+			return count
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+		defer cancel()
+
+		if n, err := db.Count(ctx); err == nil {
+			count, counted = float64(n), now
+		}
+		return count
+	}))
+}
+
+// observe records the duration of a store operation started at start.
+func (m *Metrics) observe(operation string, start time.Time, err error) {
+	outcome := "ok"
+	switch {
+	case errors.Is(err, todo.ErrNotFound):
+		outcome = "not_found"
+	case err != nil:
+		outcome = "error"
+	}
+
+	m.operations.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
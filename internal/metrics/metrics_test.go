@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+)
+
+type MetricsSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	m  *Metrics
+	db todo.DB
+}
+
+func TestMetricsSuite(t *testing.T) {
+	suite.Run(t, new(MetricsSuite))
+}
+
+func (s *MetricsSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.m = New()
+	s.db = s.m.InstrumentDB(todo.NewDB())
+	s.m.CountTodos(s.db)
+}
+
+// scrape returns the metrics in the Prometheus text exposition format.
+func (s *MetricsSuite) scrape() string {
+	w := httptest.NewRecorder()
+	s.m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	s.Equal(http.StatusOK, w.Code)
+
+	body, err := io.ReadAll(w.Body)
+	s.NoError(err)
+	return string(body)
+}
+
+func (s *MetricsSuite) TestMetricsGivenWhenTheStoreIsUsed() {
+	s.T().Run("TestMetricsGivenStoreCallsWhenTheyReturnThenTheirDurationShouldBeObservedByOutcome", func(t *testing.T) {
+		created, err := s.db.Create(context.Background(), &model.Todo{Value: "buy some milk", OwnerID: 1})
+		s.NoError(err)
+		_, err = s.db.Get(context.Background(), 1, created.ID)
+		s.NoError(err)
+		_, err = s.db.Get(context.Background(), 1, created.ID+1)
+		s.ErrorIs(err, todo.ErrNotFound)
+
+		metrics := s.scrape()
+
+		s.Contains(metrics, `todo_app_store_operation_duration_seconds_count{operation="create",outcome="ok"} 1`)
+		s.Contains(metrics, `todo_app_store_operation_duration_seconds_count{operation="get",outcome="ok"} 1`)
+		s.Contains(metrics, `todo_app_store_operation_duration_seconds_count{operation="get",outcome="not_found"} 1`)
+	})
+
+	s.T().Run("TestMetricsGivenStoredTodosWhenMetricsAreScrapedThenTheTodoGaugeShouldCountThem", func(t *testing.T) {
+		s.Contains(s.scrape(), "todo_app_todos 1\n")
+	})
+
+	s.T().Run("TestMetricsGivenRecentCountWhenMetricsAreScrapedAgainThenItShouldBeReusedUntilTheIntervalPassed", func(t *testing.T) {
+		now := time.Now()
+		s.m.now = func() time.Time { return now }
+		s.scrape()
+
+		_, err := s.db.Create(context.Background(), &model.Todo{Value: "enjoy the assignment", OwnerID: 1})
+		s.NoError(err)
+		s.Contains(s.scrape(), "todo_app_todos 1\n")
+
+		now = now.Add(countInterval)
+		s.Contains(s.scrape(), "todo_app_todos 2\n")
+	})
+}
+
+func (s *MetricsSuite) TestMetricsGivenWhenARouteIsInstrumented() {
+	s.T().Run("TestMetricsGivenServedRequestsWhenMetricsAreScrapedThenTheyShouldBeCountedAndTimedPerRoute", func(t *testing.T) {
+		handler := s.m.InstrumentRoute("GET TODO", http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusNotFound)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todo/1", nil))
+
+		metrics := s.scrape()
+
+		s.Contains(metrics, `todo_app_http_requests_total{code="404",method="get",route="GET TODO"} 1`)
+		s.Contains(metrics, `todo_app_http_request_duration_seconds_count{method="get",route="GET TODO"} 1`)
+		s.Contains(metrics, `todo_app_http_requests_in_flight{route="GET TODO"} 0`)
+	})
+}
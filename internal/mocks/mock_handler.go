@@ -93,3 +93,27 @@ func (mr *MockHandlerMockRecorder) MarkTodo(arg0, arg1 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkTodo", reflect.TypeOf((*MockHandler)(nil).MarkTodo), arg0, arg1)
 }
+
+// PatchTodo mocks base method.
+func (m *MockHandler) PatchTodo(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PatchTodo", arg0, arg1)
+}
+
+// PatchTodo indicates an expected call of PatchTodo.
+func (mr *MockHandlerMockRecorder) PatchTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTodo", reflect.TypeOf((*MockHandler)(nil).PatchTodo), arg0, arg1)
+}
+
+// TransitionTodo mocks base method.
+func (m *MockHandler) TransitionTodo(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TransitionTodo", arg0, arg1)
+}
+
+// TransitionTodo indicates an expected call of TransitionTodo.
+func (mr *MockHandlerMockRecorder) TransitionTodo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionTodo", reflect.TypeOf((*MockHandler)(nil).TransitionTodo), arg0, arg1)
+}
@@ -5,6 +5,8 @@
 package mocks
 
 import (
+	context "context"
+	slog "log/slog"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,86 +37,123 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockService) Create(arg0 *model.Todo) (*model.Todo, error) {
+func (m *MockService) Create(arg0 context.Context, arg1 *model.Todo) (*model.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(*model.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockServiceMockRecorder) Create(arg0 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), arg0, arg1)
 }
 
 // Delete mocks base method.
-func (m *MockService) Delete(arg0 int) error {
+func (m *MockService) Delete(arg0 context.Context, arg1 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockServiceMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), arg0, arg1)
 }
 
 // Get mocks base method.
-func (m *MockService) Get(arg0 int) (*model.Todo, error) {
+func (m *MockService) Get(arg0 context.Context, arg1 int) (*model.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", arg0)
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
 	ret0, _ := ret[0].(*model.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockServiceMockRecorder) Get(arg0 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), arg0, arg1)
 }
 
 // List mocks base method.
-func (m *MockService) List() []*model.Todo {
+func (m *MockService) List(arg0 context.Context, arg1 model.ListOptions) ([]*model.Todo, model.Pagination, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List")
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
 	ret0, _ := ret[0].([]*model.Todo)
-	return ret0
+	ret1, _ := ret[1].(model.Pagination)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // List indicates an expected call of List.
-func (mr *MockServiceMockRecorder) List() *gomock.Call {
+func (mr *MockServiceMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), arg0, arg1)
 }
 
 // Log mocks base method.
-func (m *MockService) Log(arg0 string) {
+func (m *MockService) Log(arg0 context.Context, arg1 slog.Level, arg2 string, arg3 ...interface{}) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Log", arg0)
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Log", varargs...)
 }
 
 // Log indicates an expected call of Log.
-func (mr *MockServiceMockRecorder) Log(arg0 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Log(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Log", reflect.TypeOf((*MockService)(nil).Log), arg0)
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Log", reflect.TypeOf((*MockService)(nil).Log), varargs...)
 }
 
 // Mark mocks base method.
-func (m *MockService) Mark(arg0 *model.Todo) (*model.Todo, error) {
+func (m *MockService) Mark(arg0 context.Context, arg1 *model.Todo) (*model.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Mark", arg0)
+	ret := m.ctrl.Call(m, "Mark", arg0, arg1)
 	ret0, _ := ret[0].(*model.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Mark indicates an expected call of Mark.
-func (mr *MockServiceMockRecorder) Mark(arg0 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Mark(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mark", reflect.TypeOf((*MockService)(nil).Mark), arg0, arg1)
+}
+
+// Patch mocks base method.
+func (m *MockService) Patch(arg0 context.Context, arg1 int, arg2 []byte) (*model.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockServiceMockRecorder) Patch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockService)(nil).Patch), arg0, arg1, arg2)
+}
+
+// Transition mocks base method.
+func (m *MockService) Transition(arg0 context.Context, arg1 int, arg2 string) (*model.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transition", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transition indicates an expected call of Transition.
+func (mr *MockServiceMockRecorder) Transition(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mark", reflect.TypeOf((*MockService)(nil).Mark), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transition", reflect.TypeOf((*MockService)(nil).Transition), arg0, arg1, arg2)
 }
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// APIKey lets a machine client act on behalf of the user owning it, limited to
+// Scopes. Only a hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	OwnerID   int        `json:"-"`
+	Hash      string     `json:"-"`
+}
+
+// NewAPIKey is the body of the API key creation request.
+type NewAPIKey struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// FromJSON decodes the request from r, rejecting unknown fields.
+func (k *NewAPIKey) FromJSON(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(k)
+}
+
+// CreatedAPIKey is returned once on creation; Key cannot be retrieved later.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
@@ -1,7 +1,9 @@
 package model
 
 import (
+	"log/slog"
 	"net/http"
+	"time"
 )
 
 type Route struct {
@@ -10,10 +12,20 @@ type Route struct {
 	Pattern     string
 	HandlerFunc http.HandlerFunc
 	Middleware  *http.HandlerFunc
+	// Public routes are served without authentication.
+	Public bool
+	// Scope an API key needs to be granted to use the route.
+	Scope string
 }
 
 type Routes []Route
 
+// HTTPResponse is implemented by every response envelope so it can be written
+// with an HTTP status matching its Code.
+type HTTPResponse interface {
+	StatusCode() int
+}
+
 type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
@@ -29,13 +41,111 @@ type GetTodoResponse struct {
 }
 
 type GetTodosResponse struct {
-	Success bool    `json:"success"`
-	Message string  `json:"message"`
-	Data    []*Todo `json:"data"`
-	Code    int     `json:"code"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	Data       []*Todo     `json:"data"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Code       int         `json:"code"`
+}
+
+func (r Response) StatusCode() int {
+	return r.Code
+}
+
+func (r GetTodoResponse) StatusCode() int {
+	return r.Code
+}
+
+func (r GetTodosResponse) StatusCode() int {
+	return r.Code
 }
 
 type Config struct {
-	AppEnv      string
-	BindAddress string
+	AppEnv          string
+	BindAddress     string
+	DBDriver        string
+	DBDSN           string
+	RequestTimeout  time.Duration
+	TokenTTL        time.Duration
+	JWTAlgorithm    string
+	JWTSecret       string
+	JWTPrivateKey   []byte
+	JWTPublicKey    []byte
+	JWTIssuer       string
+	JWTClockSkew    time.Duration
+	CORS            CORSConfig
+	LogLevel        slog.Level
+	LogFormat       string
+	TrustedProxies  []string
+	RateLimit       RateLimitConfig
+	Tracing         TracingConfig
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+	Server          ServerConfig
+
+	// InternalBindAddress is where metrics and health probes are served,
+	// apart from the API so they are not exposed with it. Empty when they are
+	// not served.
+	InternalBindAddress string
+
+	// ClaimOrphanedTodos is the username given the todos that predate
+	// accounts, which belong to nobody until then. Empty to leave them.
+	ClaimOrphanedTodos string
+}
+
+// CORSConfig is the cross-origin policy of the API. No origin is allowed when
+// AllowedOrigins is empty.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
+
+// RateLimit allows Requests per Per on average with bursts of up to Burst
+// requests. Burst defaults to Requests.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// RateLimitConfig holds the Default limit and the limits of routes, keyed by
+// Route.Name, that differ from it.
+type RateLimitConfig struct {
+	Default RateLimit
+	Routes  map[string]RateLimit
+}
+
+// TracingConfig selects where spans are exported: nowhere when Exporter is
+// empty or "none", to stdout, or to the OTLP/HTTP collector at OTLPEndpoint.
+// SampleRatio is the share of new traces that are recorded.
+type TracingConfig struct {
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	SampleRatio  float64
+}
+
+// ServerConfig tunes the HTTP server. TLS is served when TLS.CertFile is set;
+// HTTP2 is then negotiated with ALPN and otherwise spoken in cleartext (h2c).
+type ServerConfig struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	HTTP2             bool
+	TLS               TLSConfig
+}
+
+// TLSConfig holds the paths of the PEM files of the server certificate and
+// key, reloaded when they change, and of the CAs that client certificates
+// must chain to. Mutual TLS is only required when ClientCAFile is set.
+type TLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
 }
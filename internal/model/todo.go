@@ -3,17 +3,105 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"time"
 )
 
+// Todo is a single item of a todo list. The timestamps are maintained by the
+// service: values sent by clients are ignored.
 type Todo struct {
-	ID     int    `json:"id"`
-	Value  string `json:"value"`
-	Marked int    `json:"marked"`
+	ID          int        `json:"id"`
+	Value       string     `json:"value"`
+	Description string     `json:"description,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Status      string     `json:"status"`
+	// Marked is the done flag todos had before Status: 1 for done todos, 0
+	// otherwise. Clients may still send it instead of Status.
+	Marked      int        `json:"marked"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	OwnerID     int        `json:"-"`
+	// Version counts the writes of the todo, starting at 1.
+	Version int `json:"-"`
 }
 
 type Todos []*Todo
 
+// FromJSON decodes a single todo from r, rejecting unknown fields.
 func (todo *Todo) FromJSON(r io.Reader) error {
 	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
 	return decoder.Decode(todo)
 }
+
+// Statuses of the todo workflow. New todos are open unless told otherwise.
+const (
+	StatusOpen       = "open"
+	StatusInProgress = "in_progress"
+	StatusBlocked    = "blocked"
+	StatusDone       = "done"
+	StatusArchived   = "archived"
+)
+
+// MarkedFor returns the Marked flag matching status.
+func MarkedFor(status string) int {
+	if status == StatusDone {
+		return 1
+	}
+
+	return 0
+}
+
+// Transition is the body of the todo transition request.
+type Transition struct {
+	Status string `json:"status"`
+}
+
+// FromJSON decodes the request from r, rejecting unknown fields.
+func (t *Transition) FromJSON(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(t)
+}
+
+// Priority levels of a todo, from the lowest. Todos may have no priority.
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+const (
+	SortByID     = "id"
+	SortByValue  = "value"
+	SortByMarked = "marked"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// ListOptions narrows, orders and pages the todos returned by a list call.
+// A zero Limit means no limit; empty Sort and Order default to id and asc.
+// DueBefore and DueAfter are exclusive bounds and leave out todos without a
+// due date. Contains matches values ignoring the case of ASCII letters only.
+type ListOptions struct {
+	Limit     int
+	Cursor    string
+	Sort      string
+	Order     string
+	Marked    *int
+	Status    string
+	Contains  string
+	Priority  string
+	DueBefore *time.Time
+	DueAfter  *time.Time
+}
+
+// Pagination describes the page returned by a list call. NextCursor is only
+// set when HasMore is true and must be sent back to fetch the next page.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type User struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"-"`
+}
+
+// Credentials is the body of the registration and login requests.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// FromJSON decodes credentials from r, rejecting unknown fields.
+func (c *Credentials) FromJSON(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(c)
+}
+
+// Token is an access token handed out on login. Clients send it back in an
+// "Authorization: Bearer <token>" header.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,99 @@
+// Package storage opens the stores of every domain package on the backend
+// selected in the configuration, sharing one connection pool between them.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apikey"
+	"github.com/tarkanaciksoz/api-todo-app/internal/database"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+	"github.com/tarkanaciksoz/api-todo-app/internal/user"
+)
+
+const DriverMemory = "memory"
+
+type Storage struct {
+	Driver string
+	Todos  todo.DB
+	Users  user.DB
+	Keys   apikey.DB
+
+	db *sql.DB
+}
+
+// Open returns the stores for config.DBDriver. SQL backends still need
+// Migrate to be called before they are used.
+func Open(config model.Config, logger *slog.Logger) (*Storage, error) {
+	switch config.DBDriver {
+	case "", DriverMemory:
+		return NewMemory(), nil
+	case database.DriverSQLite, database.DriverPostgres:
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q", config.DBDriver)
+	}
+
+	db, err := database.Open(config.DBDriver, config.DBDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	todos := todo.NewSQLStore(db, config.DBDriver)
+	todos.L = logger
+
+	return &Storage{
+		Driver: config.DBDriver,
+		Todos:  todos,
+		Users:  user.NewSQLStore(db, config.DBDriver),
+		Keys:   apikey.NewSQLStore(db, config.DBDriver),
+		db:     db,
+	}, nil
+}
+
+// NewMemory returns in-memory stores, losing every change on restart.
+func NewMemory() *Storage {
+	return &Storage{
+		Driver: DriverMemory,
+		Todos:  todo.NewDB(),
+		Users:  user.NewDB(),
+		Keys:   apikey.NewDB(),
+	}
+}
+
+// Migrate applies the pending schema migrations of SQL backends.
+func (s *Storage) Migrate(ctx context.Context) error {
+	if s.db == nil {
+		return nil
+	}
+
+	return database.Migrate(ctx, s.db, s.Driver)
+}
+
+// ClaimOrphanedTodos gives the todos created before accounts existed to the
+// user with username and returns how many were claimed. Those todos belong to
+// nobody until claimed; in-memory stores never have any.
+func (s *Storage) ClaimOrphanedTodos(ctx context.Context, username string) (int, error) {
+	todos, ok := s.Todos.(*todo.SQLStore)
+	if !ok {
+		return 0, nil
+	}
+
+	owner, err := s.Users.GetByUsername(ctx, username)
+	if err != nil {
+		return 0, fmt.Errorf("claiming orphaned todos for %q: %w", username, err)
+	}
+
+	return todos.ClaimOrphans(ctx, owner.ID)
+}
+
+func (s *Storage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+
+	return s.db.Close()
+}
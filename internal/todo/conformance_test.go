@@ -0,0 +1,55 @@
+package todo_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo/todotest"
+)
+
+func TestMemoryConformance(t *testing.T) {
+	todotest.RunDBConformance(t, func(t *testing.T) todo.DB {
+		return todo.NewDB()
+	})
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	todotest.RunDBConformance(t, func(t *testing.T) todo.DB {
+		db, err := todo.NewSQLite(filepath.Join(t.TempDir(), "todo.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		if err := db.Migrate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	})
+}
+
+func TestPostgresConformance(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	todotest.RunDBConformance(t, func(t *testing.T) todo.DB {
+		db, err := todo.NewPostgres(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		if err := db.Migrate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.DB.Exec("TRUNCATE todos RESTART IDENTITY"); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	})
+}
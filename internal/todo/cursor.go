@@ -0,0 +1,96 @@
+package todo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// cursor points just after the last todo of a page. It records the sort it
+// was issued for so it can not be replayed against a different ordering.
+type cursor struct {
+	Sort   string `json:"s"`
+	Order  string `json:"o"`
+	ID     int    `json:"id"`
+	Value  string `json:"v,omitempty"`
+	Marked int    `json:"m,omitempty"`
+}
+
+func encodeCursor(opts model.ListOptions, last *model.Todo) string {
+	raw, _ := json.Marshal(cursor{Sort: opts.Sort, Order: opts.Order, ID: last.ID, Value: last.Value, Marked: last.Marked})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor returns nil when opts has no cursor.
+func decodeCursor(opts model.ListOptions) (*cursor, error) {
+	if opts.Cursor == "" {
+		return nil, nil
+	}
+
+	invalid := &apperr.ValidationError{Field: "cursor", Message: "is invalid for this sort"}
+
+	raw, err := base64.RawURLEncoding.DecodeString(opts.Cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	c := &cursor{}
+	if err := json.Unmarshal(raw, c); err != nil || c.Sort != opts.Sort || c.Order != opts.Order {
+		return nil, invalid
+	}
+
+	return c, nil
+}
+
+// normalizeListOptions fills in the default sort and order so stores and
+// cursors always see the same explicit values, and validates the filters.
+func normalizeListOptions(opts model.ListOptions) (model.ListOptions, error) {
+	switch opts.Sort {
+	case "":
+		opts.Sort = model.SortByID
+	case model.SortByID, model.SortByValue, model.SortByMarked:
+	default:
+		return opts, &apperr.ValidationError{Field: "sort", Message: "must be one of id, value, marked"}
+	}
+
+	switch opts.Order {
+	case "":
+		opts.Order = model.OrderAsc
+	case model.OrderAsc, model.OrderDesc:
+	default:
+		return opts, &apperr.ValidationError{Field: "order", Message: "must be asc or desc"}
+	}
+
+	if opts.Limit < 0 {
+		return opts, &apperr.ValidationError{Field: "limit", Message: "must not be negative"}
+	}
+
+	if opts.Marked != nil && *opts.Marked != 0 && *opts.Marked != 1 {
+		return opts, &apperr.ValidationError{Field: "marked", Message: "must be 0 or 1"}
+	}
+
+	if opts.Status != "" && !validStatus(opts.Status) {
+		return opts, &apperr.ValidationError{Field: "status", Message: statusMessage}
+	}
+
+	if opts.Priority != "" && !validPriority(opts.Priority) {
+		return opts, &apperr.ValidationError{Field: "priority", Message: "must be one of low, medium, high"}
+	}
+
+	return opts, nil
+}
+
+// paginate trims a result fetched with one extra row to opts.Limit and builds
+// the matching Pagination.
+func paginate(opts model.ListOptions, todos []*model.Todo) ([]*model.Todo, model.Pagination) {
+	page := model.Pagination{Limit: opts.Limit}
+	if opts.Limit > 0 && len(todos) > opts.Limit {
+		todos = todos[:opts.Limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(opts, todos[len(todos)-1])
+	}
+
+	return todos, page
+}
@@ -0,0 +1,43 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+)
+
+// Sentinel errors returned by the Service and DB implementations. Callers
+// should match them with errors.Is since they are usually wrapped with details.
+// Errors shared with other packages live in apperr.
+var (
+	ErrNotFound  = errors.New("no todo found")
+	ErrInvalidID = errors.New("todo ID Must Be Greater Than Zero")
+	ErrConflict  = fmt.Errorf("todo %w", apperr.ErrConflict)
+
+	ErrInvalidTransition = errors.New("todo can not move")
+)
+
+func errNotFound(id int) error {
+	return fmt.Errorf("%w with id:%d", ErrNotFound, id)
+}
+
+func errConflict(id int) error {
+	return fmt.Errorf("%w with id:%d, it was changed by another request", ErrConflict, id)
+}
+
+// StatusCode maps an error returned by the todo package to an HTTP status.
+// Errors shared with other packages fall back to apperr.StatusCode.
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidID):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInvalidTransition):
+		return http.StatusConflict
+	default:
+		return apperr.StatusCode(err)
+	}
+}
@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+)
+
+type ErrorsSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestErrorsSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsSuite))
+}
+
+func (s *ErrorsSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+}
+
+func (s *ErrorsSuite) TestErrorsGivenWhenStatusCodeIsCalled() {
+	cases := map[string]struct {
+		err      error
+		expected int
+	}{
+		"NotFound":          {errNotFound(1), http.StatusNotFound},
+		"InvalidID":         {ErrInvalidID, http.StatusBadRequest},
+		"Conflict":          {errConflict(1), http.StatusConflict},
+		"InvalidTransition": {checkTransition("done", "blocked"), http.StatusConflict},
+		"SharedError":       {&apperr.ValidationError{Field: "value", Message: "must not be empty"}, http.StatusUnprocessableEntity},
+	}
+
+	for name, c := range cases {
+		s.T().Run("TestErrorsGiven"+name+"WhenStatusCodeIsCalledThenItShouldReturn"+http.StatusText(c.expected), func(t *testing.T) {
+			s.Equal(c.expected, StatusCode(c.err))
+		})
+	}
+}
+
+func (s *ErrorsSuite) TestErrorsGivenWhenErrConflictIsWrapped() {
+	s.T().Run("TestErrorsGivenIdWhenErrConflictIsCalledThenItShouldMatchTheSharedConflict", func(t *testing.T) {
+		err := errConflict(1)
+
+		s.ErrorIs(err, ErrConflict)
+		s.ErrorIs(err, apperr.ErrConflict)
+		s.EqualError(err, "todo conflict with id:1, it was changed by another request")
+	})
+}
+
+func (s *ErrorsSuite) TestErrorsGivenWhenErrNotFoundIsWrapped() {
+	s.T().Run("TestErrorsGivenIdWhenErrNotFoundIsCalledThenItShouldKeepTheLegacyMessage", func(t *testing.T) {
+		err := errNotFound(1)
+
+		s.ErrorIs(err, ErrNotFound)
+		s.EqualError(err, "no todo found with id:1")
+	})
+}
@@ -1,11 +1,16 @@
 package todo
 
 import (
-	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
 	"github.com/tarkanaciksoz/api-todo-app/internal/model"
 	"github.com/tarkanaciksoz/api-todo-app/internal/util"
 )
@@ -16,9 +21,11 @@ type TodoHandler struct {
 
 type Handler interface {
 	GetTodo(rw http.ResponseWriter, r *http.Request)
-	ListTodos(rw http.ResponseWriter, _ *http.Request)
+	ListTodos(rw http.ResponseWriter, r *http.Request)
 	CreateTodo(rw http.ResponseWriter, r *http.Request)
 	MarkTodo(rw http.ResponseWriter, r *http.Request)
+	PatchTodo(rw http.ResponseWriter, r *http.Request)
+	TransitionTodo(rw http.ResponseWriter, r *http.Request)
 	DeleteTodo(rw http.ResponseWriter, r *http.Request)
 }
 
@@ -29,110 +36,242 @@ func NewTodoHandler(ts Service) Handler {
 }
 
 func (th *TodoHandler) GetTodo(rw http.ResponseWriter, r *http.Request) {
-	th.ts.Log("Handle GetTodo method")
+	th.ts.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "GetTodo")
 
 	vars := mux.Vars(r)
 
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		th.ts.Log("Unable to resolve id " + vars["id"] + ": " + err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Unable to resolve id : "+vars["id"], nil, http.StatusBadRequest))
+		th.ts.Log(r.Context(), slog.LevelInfo, "unable to convert id", "id", vars["id"], "error", err)
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Unable to resolve id : "+vars["id"], nil, http.StatusBadRequest))
 		return
 	}
 
-	todo, err := th.ts.Get(id)
+	todo, err := th.ts.Get(r.Context(), id)
 	if err != nil {
-		th.ts.Log(err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, err.Error(), nil, http.StatusBadRequest))
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
 		return
 	}
 
-	json.NewEncoder(rw).Encode(util.SetAndGetTodoResponse(true, "Todo Listed Successfully", todo, http.StatusOK))
-	th.ts.Log("GetTodo method successfully handled")
+	util.WriteResponse(rw, util.SetAndGetTodoResponse(true, "Todo Listed Successfully", todo, http.StatusOK))
+	th.ts.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "GetTodo")
 }
 
-func (th *TodoHandler) ListTodos(rw http.ResponseWriter, _ *http.Request) {
-	th.ts.Log("Handle ListTodos method")
+func (th *TodoHandler) ListTodos(rw http.ResponseWriter, r *http.Request) {
+	th.ts.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "ListTodos")
 
-	todos := th.ts.List()
+	opts, err := listOptionsFromQuery(r.URL.Query())
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
+		return
+	}
 
-	json.NewEncoder(rw).Encode(util.SetAndGetTodosResponse(true, "Todos Listed Successfully", todos, http.StatusOK))
-	th.ts.Log("ListTodos method successfully handled")
+	todos, page, err := th.ts.List(r.Context(), opts)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
+		return
+	}
+
+	response := util.SetAndGetTodosResponse(true, "Todos Listed Successfully", todos, http.StatusOK)
+	response.Pagination = &page
+	util.WriteResponse(rw, response)
+	th.ts.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "ListTodos")
 }
 
 func (th *TodoHandler) CreateTodo(rw http.ResponseWriter, r *http.Request) {
-	th.ts.Log("Handle CreateTodo method")
+	th.ts.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "CreateTodo")
 
-	todo := &model.Todo{}
-	err := todo.FromJSON(r.Body)
+	todo, err := decodeTodo(rw, r)
 	if err != nil {
-		th.ts.Log("Request Body Couldn't Resolved - Invalid JSON Data : " + err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Invalid JSON Data", nil, http.StatusBadRequest))
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
 		return
 	}
 
-	todo, err = th.ts.Create(todo)
+	todo, err = th.ts.Create(r.Context(), todo)
 	if err != nil {
-		th.ts.Log(err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, err.Error(), nil, http.StatusBadRequest))
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
 		return
 	}
 
-	json.NewEncoder(rw).Encode(util.SetAndGetTodoResponse(true, "Todo Created Successfully", todo, http.StatusOK))
-	th.ts.Log("CreateTodo method successfully handled")
+	util.WriteResponse(rw, util.SetAndGetTodoResponse(true, "Todo Created Successfully", todo, http.StatusOK))
+	th.ts.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "CreateTodo")
 }
 
 func (th *TodoHandler) MarkTodo(rw http.ResponseWriter, r *http.Request) {
-	th.ts.Log("Handle MarkTodo method")
+	th.ts.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "MarkTodo")
 
 	vars := mux.Vars(r)
 
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		th.ts.Log("Unable to convert id " + vars["id"] + ": " + err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Unable to convert id : "+vars["id"], nil, http.StatusBadRequest))
+		th.ts.Log(r.Context(), slog.LevelInfo, "unable to convert id", "id", vars["id"], "error", err)
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Unable to convert id : "+vars["id"], nil, http.StatusBadRequest))
 		return
 	}
 
-	todo := &model.Todo{}
-	err = todo.FromJSON(r.Body)
+	todo, err := decodeTodo(rw, r)
 	if err != nil {
-		th.ts.Log("Request Body Couldn't Resolved - Invalid JSON Data : " + err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Invalid JSON Data", nil, http.StatusBadRequest))
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
 		return
 	}
 	todo.ID = id
 
-	todo, err = th.ts.Mark(todo)
+	todo, err = th.ts.Mark(r.Context(), todo)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
+		return
+	}
+
+	util.WriteResponse(rw, util.SetAndGetTodoResponse(true, "Todo Marked Successfully", todo, http.StatusOK))
+	th.ts.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "MarkTodo")
+}
+
+func (th *TodoHandler) PatchTodo(rw http.ResponseWriter, r *http.Request) {
+	th.ts.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "PatchTodo")
+
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		th.ts.Log(r.Context(), slog.LevelInfo, "unable to convert id", "id", vars["id"], "error", err)
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Unable to convert id : "+vars["id"], nil, http.StatusBadRequest))
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != MergePatchContentType && mediaType != "application/json" {
+		th.ts.Log(r.Context(), slog.LevelInfo, "unsupported content type", "content_type", r.Header.Get("Content-Type"))
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Content-Type Must Be "+MergePatchContentType, nil, http.StatusUnsupportedMediaType))
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, apperr.MaxBodyBytes))
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, apperr.BodyError(err), StatusCode, th.ts.Log)
+		return
+	}
+
+	todo, err := th.ts.Patch(r.Context(), id, patch)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
+		return
+	}
+
+	util.WriteResponse(rw, util.SetAndGetTodoResponse(true, "Todo Patched Successfully", todo, http.StatusOK))
+	th.ts.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "PatchTodo")
+}
+
+func (th *TodoHandler) TransitionTodo(rw http.ResponseWriter, r *http.Request) {
+	th.ts.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "TransitionTodo")
+
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		th.ts.Log(r.Context(), slog.LevelInfo, "unable to convert id", "id", vars["id"], "error", err)
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Unable to convert id : "+vars["id"], nil, http.StatusBadRequest))
+		return
+	}
+
+	transition := &model.Transition{}
+	if err := transition.FromJSON(http.MaxBytesReader(rw, r.Body, apperr.MaxBodyBytes)); err != nil {
+		apperr.WriteError(r.Context(), rw, apperr.BodyError(err), StatusCode, th.ts.Log)
+		return
+	}
+
+	todo, err := th.ts.Transition(r.Context(), id, transition.Status)
 	if err != nil {
-		th.ts.Log(err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, err.Error(), nil, http.StatusBadRequest))
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
 		return
 	}
 
-	json.NewEncoder(rw).Encode(util.SetAndGetTodoResponse(true, "Todo Marked Successfully", todo, http.StatusOK))
-	th.ts.Log("MarkTodo method successfully handled")
+	util.WriteResponse(rw, util.SetAndGetTodoResponse(true, "Todo Transitioned Successfully", todo, http.StatusOK))
+	th.ts.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "TransitionTodo")
 }
 
 func (th *TodoHandler) DeleteTodo(rw http.ResponseWriter, r *http.Request) {
-	th.ts.Log("Handle DeleteTodo method")
+	th.ts.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "DeleteTodo")
 
 	vars := mux.Vars(r)
 
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		th.ts.Log("Unable to convert id " + vars["id"] + ": " + err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Unable to convert id : "+vars["id"], nil, http.StatusBadRequest))
+		th.ts.Log(r.Context(), slog.LevelInfo, "unable to convert id", "id", vars["id"], "error", err)
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Unable to convert id : "+vars["id"], nil, http.StatusBadRequest))
 		return
 	}
 
-	err = th.ts.Delete(id)
+	err = th.ts.Delete(r.Context(), id)
 	if err != nil {
-		th.ts.Log(err.Error())
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, err.Error(), nil, http.StatusBadRequest))
+		apperr.WriteError(r.Context(), rw, err, StatusCode, th.ts.Log)
 		return
 	}
 
-	json.NewEncoder(rw).Encode(util.SetAndGetResponse(true, "Todo Deleted Successfully", nil, http.StatusOK))
-	th.ts.Log("DeleteTodo method successfully handled")
+	util.WriteResponse(rw, util.SetAndGetResponse(true, "Todo Deleted Successfully", nil, http.StatusOK))
+	th.ts.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "DeleteTodo")
+}
+
+// decodeTodo reads a todo from a request body of at most apperr.MaxBodyBytes.
+func decodeTodo(rw http.ResponseWriter, r *http.Request) (*model.Todo, error) {
+	todo := &model.Todo{}
+	if err := todo.FromJSON(http.MaxBytesReader(rw, r.Body, apperr.MaxBodyBytes)); err != nil {
+		return nil, apperr.BodyError(err)
+	}
+
+	return todo, nil
+}
+
+// listOptionsFromQuery reads the limit, cursor, sort, order, marked, status,
+// q, priority, due_before and due_after query parameters of GET /todo. Due
+// dates are RFC 3339 date-times.
+func listOptionsFromQuery(query url.Values) (model.ListOptions, error) {
+	opts := model.ListOptions{
+		Cursor:   query.Get("cursor"),
+		Sort:     query.Get("sort"),
+		Order:    query.Get("order"),
+		Status:   query.Get("status"),
+		Contains: query.Get("q"),
+		Priority: query.Get("priority"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return opts, &apperr.ValidationError{Field: "limit", Message: "must be a number"}
+		}
+		opts.Limit = n
+	}
+
+	if marked := query.Get("marked"); marked != "" {
+		n, err := strconv.Atoi(marked)
+		if err != nil {
+			return opts, &apperr.ValidationError{Field: "marked", Message: "must be 0 or 1"}
+		}
+		opts.Marked = &n
+	}
+
+	var err error
+	if opts.DueBefore, err = dueBound(query, "due_before"); err != nil {
+		return opts, err
+	}
+	if opts.DueAfter, err = dueBound(query, "due_after"); err != nil {
+		return opts, err
+	}
+
+	return opts, nil
+}
+
+// dueBound parses the due date bound in field, nil when it is not set.
+func dueBound(query url.Values, field string) (*time.Time, error) {
+	raw := query.Get(field)
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, &apperr.ValidationError{Field: field, Message: "must be an RFC 3339 date-time"}
+	}
+
+	return &t, nil
 }
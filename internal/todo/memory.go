@@ -1,98 +1,251 @@
 package todo
 
 import (
-	"errors"
+	"context"
 	"sort"
-	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/tarkanaciksoz/api-todo-app/internal/model"
 )
 
+// Memory is the DB todos live in when no database is configured. It is safe
+// for concurrent use: reads run in parallel while writes are serialized.
+// Todos are copied on the way in and out so callers never share state with
+// the store. Like SQLStore, it derives Marked from Status.
 type Memory struct {
-	Todos map[int]*model.Todo
+	mu     sync.RWMutex
+	lastID int
+	Todos  map[int]*model.Todo
 }
 
 type DB interface {
-	Get(id int) (*model.Todo, error)
-	List() []*model.Todo
-	Create(t *model.Todo) (*model.Todo, error)
-	Mark(t *model.Todo) (*model.Todo, error)
-	Delete(id int) error
+	Get(ctx context.Context, ownerID int, id int) (*model.Todo, error)
+	List(ctx context.Context, ownerID int, opts model.ListOptions) ([]*model.Todo, model.Pagination, error)
+	Create(ctx context.Context, t *model.Todo) (*model.Todo, error)
+	// Mark replaces the stored todo as long as it still has the given version,
+	// the one its new state was computed from, and bumps the version.
+	// Otherwise it returns ErrConflict so that concurrent writes can not
+	// overwrite each other.
+	Mark(ctx context.Context, t *model.Todo, version int) (*model.Todo, error)
+	Delete(ctx context.Context, ownerID int, id int) error
+	// Count returns the number of todos of every owner.
+	Count(ctx context.Context) (int, error)
+	// Ping reports whether the backend can currently serve requests.
+	Ping(ctx context.Context) error
 }
 
 func NewDB() DB {
-	return Memory{
+	return &Memory{
 		Todos: make(map[int]*model.Todo),
 	}
 }
 
-func (m Memory) Get(id int) (*model.Todo, error) {
+func (m *Memory) Get(ctx context.Context, ownerID int, id int) (*model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if !(id > 0) {
-		return nil, errors.New("todo ID Must Be Greater Than Zero")
+		return nil, ErrInvalidID
 	}
 
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	todo, exists := m.Todos[id]
-	if !exists {
-		return nil, errors.New("no todo found with id:" + strconv.Itoa(id))
+	if !exists || todo.OwnerID != ownerID {
+		return nil, errNotFound(id)
 	}
 
-	return todo, nil
+	return copyTodo(todo), nil
 }
 
-func (m Memory) List() []*model.Todo {
-	todos := []*model.Todo{}
+func (m *Memory) List(ctx context.Context, ownerID int, opts model.ListOptions) ([]*model.Todo, model.Pagination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, model.Pagination{}, err
+	}
 
-	keys := make([]int, 0, len(m.Todos))
-	for k := range m.Todos {
-		keys = append(keys, k)
+	opts, err := normalizeListOptions(opts)
+	if err != nil {
+		return nil, model.Pagination{}, err
 	}
 
-	sort.Ints(keys)
+	after, err := decodeCursor(opts)
+	if err != nil {
+		return nil, model.Pagination{}, err
+	}
 
-	for _, k := range keys {
-		todos = append(todos, m.Todos[k])
+	m.mu.RLock()
+	todos := []*model.Todo{}
+	for _, todo := range m.Todos {
+		if todo.OwnerID == ownerID && matches(opts, todo) {
+			todos = append(todos, copyTodo(todo))
+		}
 	}
+	m.mu.RUnlock()
 
-	return todos
-}
+	sort.Slice(todos, func(i, j int) bool {
+		return less(opts, todos[i], todos[j])
+	})
 
-func (m Memory) Create(todo *model.Todo) (*model.Todo, error) {
-	id := todo.ID
+	if after != nil {
+		last := &model.Todo{ID: after.ID, Value: after.Value, Marked: after.Marked}
+		todos = todos[sort.Search(len(todos), func(i int) bool {
+			return less(opts, last, todos[i])
+		}):]
+	}
 
-	if !(id > 0) {
-		return nil, errors.New("todo ID Must Be Greater Than Zero")
+	if opts.Limit > 0 && len(todos) > opts.Limit+1 {
+		todos = todos[:opts.Limit+1]
 	}
 
-	for {
-		_, exists := m.Todos[id]
-		if !exists {
-			break
-		}
-		id++
+	todos, page := paginate(opts, todos)
+	return todos, page, nil
+}
+
+// Create stores the todo under the next auto-increment ID. Any ID sent by the
+// client is ignored and IDs are never reused, even after Delete.
+func (m *Memory) Create(ctx context.Context, todo *model.Todo) (*model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	todo.ID = id
-	m.Todos[id] = todo
-	return m.Todos[id], nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+
+	stored := copyTodo(todo)
+	stored.ID = m.lastID
+	stored.Marked = model.MarkedFor(stored.Status)
+	stored.Version = 1
+	m.Todos[stored.ID] = stored
+	return copyTodo(stored), nil
 }
 
-func (m Memory) Mark(todo *model.Todo) (*model.Todo, error) {
-	_, exists := m.Todos[todo.ID]
-	if !exists {
-		return nil, errors.New("no todo found with id:" + strconv.Itoa(todo.ID))
+func (m *Memory) Mark(ctx context.Context, todo *model.Todo, version int) (*model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, exists := m.Todos[todo.ID]
+	if !exists || stored.OwnerID != todo.OwnerID {
+		return nil, errNotFound(todo.ID)
+	}
+	if stored.Version != version {
+		return nil, errConflict(todo.ID)
 	}
 
-	m.Todos[todo.ID] = todo
+	stored = copyTodo(todo)
+	stored.Marked = model.MarkedFor(stored.Status)
+	stored.Version = version + 1
+	m.Todos[todo.ID] = stored
 
-	return m.Todos[todo.ID], nil
+	return copyTodo(stored), nil
 }
 
-func (m Memory) Delete(id int) error {
-	_, exists := m.Todos[id]
-	if !exists {
-		return errors.New("no todo found with id:" + strconv.Itoa(id))
+func (m *Memory) Delete(ctx context.Context, ownerID int, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, exists := m.Todos[id]
+	if !exists || todo.OwnerID != ownerID {
+		return errNotFound(id)
 	}
 
 	delete(m.Todos, id)
 	return nil
 }
+
+func (m *Memory) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.Todos), nil
+}
+
+// Ping only fails for done contexts; Memory is always reachable.
+func (m *Memory) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func matches(opts model.ListOptions, todo *model.Todo) bool {
+	if opts.Marked != nil && todo.Marked != *opts.Marked {
+		return false
+	}
+
+	if opts.Status != "" && todo.Status != opts.Status {
+		return false
+	}
+
+	if opts.Priority != "" && todo.Priority != opts.Priority {
+		return false
+	}
+
+	if opts.DueBefore != nil && (todo.DueDate == nil || !todo.DueDate.Before(*opts.DueBefore)) {
+		return false
+	}
+
+	if opts.DueAfter != nil && (todo.DueDate == nil || !todo.DueDate.After(*opts.DueAfter)) {
+		return false
+	}
+
+	return strings.Contains(foldASCII(todo.Value), foldASCII(opts.Contains))
+}
+
+// foldASCII lowercases the ASCII letters of s only, which is how SQLite's
+// LOWER and LIKE fold case, so that every store matches the same todos.
+func foldASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+}
+
+// less orders todos by the sort field of opts, using the ID as tie-breaker.
+func less(opts model.ListOptions, a *model.Todo, b *model.Todo) bool {
+	if opts.Order == model.OrderDesc {
+		a, b = b, a
+	}
+
+	switch opts.Sort {
+	case model.SortByValue:
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+	case model.SortByMarked:
+		if a.Marked != b.Marked {
+			return a.Marked < b.Marked
+		}
+	}
+
+	return a.ID < b.ID
+}
+
+func copyTodo(todo *model.Todo) *model.Todo {
+	c := *todo
+	if todo.DueDate != nil {
+		due := *todo.DueDate
+		c.DueDate = &due
+	}
+	if todo.CompletedAt != nil {
+		completed := *todo.CompletedAt
+		c.CompletedAt = &completed
+	}
+
+	return &c
+}
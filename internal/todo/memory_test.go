@@ -1,7 +1,9 @@
 package todo
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -43,9 +45,9 @@ func (s *DBSuite) TestMemoryGivenWhenGetIsCalled() {
 			Marked: 0,
 		}
 
-		s.mockDB.EXPECT().Get(1).Return(expectedResponse, nil).Times(1)
+		s.mockDB.EXPECT().Get(gomock.Any(), 1, 1).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockDB.Get(1)
+		actualResponse, actualErr := s.mockDB.Get(context.Background(), 1, 1)
 
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
@@ -55,8 +57,8 @@ func (s *DBSuite) TestMemoryGivenWhenGetIsCalled() {
 		expectedResponse := &model.Todo{}
 		expectedErr := errors.New("no todo found with id:1")
 
-		s.mockDB.EXPECT().Get(1).Return(expectedResponse, expectedErr).Times(1)
-		actualResponse, actualErr := s.mockDB.Get(1)
+		s.mockDB.EXPECT().Get(gomock.Any(), 1, 1).Return(expectedResponse, expectedErr).Times(1)
+		actualResponse, actualErr := s.mockDB.Get(context.Background(), 1, 1)
 
 		s.EqualError(actualErr, expectedErr.Error())
 		s.Equal(expectedResponse, actualResponse)
@@ -66,9 +68,10 @@ func (s *DBSuite) TestMemoryGivenWhenGetIsCalled() {
 func (s *DBSuite) TestMemoryGivenWhenListIsCalled() {
 	s.T().Run("TestMemoryGivenEmptyTodoListWhenListIsCalledThenItShouldReturnEmptyTodoList", func(t *testing.T) {
 		expectedResponse := []*model.Todo{}
-		s.mockDB.EXPECT().List().Return(expectedResponse).Times(1)
+		s.mockDB.EXPECT().List(gomock.Any(), 1, model.ListOptions{}).Return(expectedResponse, model.Pagination{}, nil).Times(1)
 
-		actualResponse := s.mockDB.List()
+		actualResponse, _, actualErr := s.mockDB.List(context.Background(), 1, model.ListOptions{})
+		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
 	})
 
@@ -85,9 +88,10 @@ func (s *DBSuite) TestMemoryGivenWhenListIsCalled() {
 				Marked: 0,
 			},
 		}
-		s.mockDB.EXPECT().List().Return(expectedResponse).Times(1)
+		s.mockDB.EXPECT().List(gomock.Any(), 1, model.ListOptions{}).Return(expectedResponse, model.Pagination{}, nil).Times(1)
 
-		actualResponse := s.mockDB.List()
+		actualResponse, _, actualErr := s.mockDB.List(context.Background(), 1, model.ListOptions{})
+		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
 	})
 }
@@ -104,9 +108,9 @@ func (s *DBSuite) TestMemoryGivenWhenCreateIsCalled() {
 			Value:  "buy some milk",
 			Marked: 0,
 		}
-		s.mockDB.EXPECT().Create(createTodoRequest).Return(expectedResponse, nil).Times(1)
+		s.mockDB.EXPECT().Create(gomock.Any(), createTodoRequest).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockDB.Create(createTodoRequest)
+		actualResponse, actualErr := s.mockDB.Create(context.Background(), createTodoRequest)
 
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
@@ -123,9 +127,9 @@ func (s *DBSuite) TestMemoryGivenWhenCreateIsCalled() {
 			Value:  "buy some milk",
 			Marked: 0,
 		}
-		s.mockDB.EXPECT().Create(createTodoRequest).Return(expectedResponse, nil).Times(1)
+		s.mockDB.EXPECT().Create(gomock.Any(), createTodoRequest).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockDB.Create(createTodoRequest)
+		actualResponse, actualErr := s.mockDB.Create(context.Background(), createTodoRequest)
 
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
@@ -143,9 +147,9 @@ func (s *DBSuite) TestMemoryGivenWhenMarkIsCalled() {
 			Value:  "buy some milk",
 			Marked: 1,
 		}
-		s.mockDB.EXPECT().Mark(markTodoRequest).Return(expectedResponse, nil).Times(1)
+		s.mockDB.EXPECT().Mark(gomock.Any(), markTodoRequest, 1).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockDB.Mark(markTodoRequest)
+		actualResponse, actualErr := s.mockDB.Mark(context.Background(), markTodoRequest, 1)
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
 	})
@@ -158,8 +162,8 @@ func (s *DBSuite) TestMemoryGivenWhenMarkIsCalled() {
 		expectedResponse := &model.Todo{}
 		expectedErr := errors.New("no todo found with id:1")
 
-		s.mockDB.EXPECT().Mark(markTodoRequest).Return(expectedResponse, expectedErr).Times(1)
-		actualResponse, actualErr := s.mockDB.Mark(markTodoRequest)
+		s.mockDB.EXPECT().Mark(gomock.Any(), markTodoRequest, 1).Return(expectedResponse, expectedErr).Times(1)
+		actualResponse, actualErr := s.mockDB.Mark(context.Background(), markTodoRequest, 1)
 
 		s.EqualError(actualErr, expectedErr.Error())
 		s.Equal(expectedResponse, actualResponse)
@@ -175,9 +179,9 @@ func (s *DBSuite) TestMemoryGivenWhenMarkIsCalled() {
 			Value:  "buy some milk",
 			Marked: 0,
 		}
-		s.mockDB.EXPECT().Mark(markTodoRequest).Return(expectedResponse, nil).Times(1)
+		s.mockDB.EXPECT().Mark(gomock.Any(), markTodoRequest, 1).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockDB.Mark(markTodoRequest)
+		actualResponse, actualErr := s.mockDB.Mark(context.Background(), markTodoRequest, 1)
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
 	})
@@ -190,8 +194,8 @@ func (s *DBSuite) TestMemoryGivenWhenMarkIsCalled() {
 		expectedResponse := &model.Todo{}
 		expectedErr := errors.New("no todo found with id:1")
 
-		s.mockDB.EXPECT().Mark(markTodoRequest).Return(expectedResponse, expectedErr).Times(1)
-		actualResponse, actualErr := s.mockDB.Mark(markTodoRequest)
+		s.mockDB.EXPECT().Mark(gomock.Any(), markTodoRequest, 1).Return(expectedResponse, expectedErr).Times(1)
+		actualResponse, actualErr := s.mockDB.Mark(context.Background(), markTodoRequest, 1)
 
 		s.EqualError(actualErr, expectedErr.Error())
 		s.Equal(expectedResponse, actualResponse)
@@ -200,17 +204,120 @@ func (s *DBSuite) TestMemoryGivenWhenMarkIsCalled() {
 
 func (s *DBSuite) TestMemoryGivenWhenDeleteIsCalled() {
 	s.T().Run("TestMemoryGivenExistingIdWhenDeleteIsCalledThenItShouldReturnNilError", func(t *testing.T) {
-		s.mockDB.EXPECT().Delete(1).Return(nil).Times(1)
-		actualErr := s.mockDB.Delete(1)
+		s.mockDB.EXPECT().Delete(gomock.Any(), 1, 1).Return(nil).Times(1)
+		actualErr := s.mockDB.Delete(context.Background(), 1, 1)
 		s.NoError(actualErr)
 	})
 
 	s.T().Run("TestMemoryGivenUnExistingIdWhenDeleteIsCalledThenItShouldReturnAnError", func(t *testing.T) {
 		expectedError := errors.New("no todo found with id:1")
 
-		s.mockDB.EXPECT().Delete(1).Return(expectedError).Times(1)
-		actualErr := s.mockDB.Delete(1)
+		s.mockDB.EXPECT().Delete(gomock.Any(), 1, 1).Return(expectedError).Times(1)
+		actualErr := s.mockDB.Delete(context.Background(), 1, 1)
 
 		s.EqualError(actualErr, expectedError.Error())
 	})
 }
+
+func (s *DBSuite) TestMemoryGivenConcurrentRequests() {
+	s.T().Run("TestMemoryGivenConcurrentCreatesWhenCreateIsCalledThenEveryTodoShouldBeStoredWithAUniqueId", func(t *testing.T) {
+		db := NewDB()
+		workers, perWorker := 16, 50
+
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					_, err := db.Create(context.Background(), &model.Todo{Value: "buy some milk"})
+					if err != nil {
+						t.Error(err)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		todos, _, err := db.List(context.Background(), 0, model.ListOptions{})
+		s.NoError(err)
+		s.Len(todos, workers*perWorker)
+
+		seen := make(map[int]bool, len(todos))
+		for _, todo := range todos {
+			s.False(seen[todo.ID], "duplicate id %d", todo.ID)
+			seen[todo.ID] = true
+		}
+	})
+
+	s.T().Run("TestMemoryGivenConcurrentReadsAndWritesWhenEveryMethodIsCalledThenItShouldNotRace", func(t *testing.T) {
+		db := NewDB()
+		for i := 1; i <= 10; i++ {
+			_, err := db.Create(context.Background(), &model.Todo{Value: "buy some milk"})
+			s.NoError(err)
+		}
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(4)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					db.Get(context.Background(), 0, i%10+1)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					todos, _, _ := db.List(context.Background(), 0, model.ListOptions{})
+					for _, todo := range todos {
+						todo.Marked = 1
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					// Retry on conflict so that every write really happens.
+					for {
+						stored, err := db.Get(context.Background(), 0, i%10+1)
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						_, err = db.Mark(context.Background(), &model.Todo{ID: stored.ID, Value: "buy some milk", Marked: i % 2}, stored.Version)
+						if errors.Is(err, ErrConflict) {
+							continue
+						}
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						break
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					todo, err := db.Create(context.Background(), &model.Todo{Value: "enjoy the assignment"})
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					db.Delete(context.Background(), 0, todo.ID)
+				}
+			}()
+		}
+		wg.Wait()
+
+		todos, _, err := db.List(context.Background(), 0, model.ListOptions{})
+		s.NoError(err)
+		s.Len(todos, 10)
+		for _, todo := range todos {
+			// Each todo was created at version 1 and marked 20 times by each
+			// of the 8 writers.
+			s.Equal(1+8*20, todo.Version)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch documents.
+const MergePatchContentType = "application/merge-patch+json"
+
+// applyMergePatch applies a JSON Merge Patch (RFC 7396) document to todo and
+// returns the patched copy. The ID can not be changed and the required members
+// can not be removed; such patches fail with an apperr.ValidationError.
+func applyMergePatch(todo *model.Todo, patch []byte) (*model.Todo, error) {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperr.ErrInvalidJSON, err.Error())
+	}
+	patchObj, ok := patchDoc.(map[string]interface{})
+	if !ok {
+		return nil, &apperr.ValidationError{Field: "body", Message: "merge patch must be a JSON object"}
+	}
+	for _, field := range []string{"id", "value", "status", "marked"} {
+		if value, ok := patchObj[field]; ok && value == nil {
+			return nil, &apperr.ValidationError{Field: field, Message: "can not be removed"}
+		}
+	}
+
+	original, err := json.Marshal(todo)
+	if err != nil {
+		return nil, err
+	}
+	targetDoc := map[string]interface{}{}
+	if err := json.Unmarshal(original, &targetDoc); err != nil {
+		return nil, err
+	}
+
+	// marked follows status, so a patch setting only one of them must not be
+	// contradicted by the current value of the other.
+	_, setsStatus := patchObj["status"]
+	_, setsMarked := patchObj["marked"]
+	switch {
+	case setsMarked && !setsStatus:
+		delete(targetDoc, "status")
+	case setsStatus && !setsMarked:
+		delete(targetDoc, "marked")
+	}
+
+	mergedDoc := mergePatch(targetDoc, patchDoc)
+
+	merged, err := json.Marshal(mergedDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	patched := &model.Todo{}
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(patched); err != nil {
+		return nil, &apperr.ValidationError{Field: "body", Message: err.Error()}
+	}
+
+	if patched.ID != todo.ID {
+		return nil, &apperr.ValidationError{Field: "id", Message: fmt.Sprintf("can not be changed from %d", todo.ID)}
+	}
+
+	return patched, nil
+}
+
+// mergePatch implements the MergePatch function from RFC 7396, section 2.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = mergePatch(targetObj[name], value)
+	}
+
+	return targetObj
+}
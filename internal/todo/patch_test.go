@@ -0,0 +1,123 @@
+package todo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+type PatchSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	todo *model.Todo
+}
+
+func TestPatchSuite(t *testing.T) {
+	suite.Run(t, new(PatchSuite))
+}
+
+func (s *PatchSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.todo = &model.Todo{ID: 1, Value: "buy some milk", Marked: 0}
+}
+
+func (s *PatchSuite) TestPatchGivenWhenApplyMergePatchIsCalled() {
+	s.T().Run("TestPatchGivenOnlyMarkedWhenApplyMergePatchIsCalledThenItShouldKeepTheValue", func(t *testing.T) {
+		actual, err := applyMergePatch(s.todo, []byte(`{"marked":1}`))
+
+		s.NoError(err)
+		s.Equal(&model.Todo{ID: 1, Value: "buy some milk", Marked: 1}, actual)
+	})
+
+	s.T().Run("TestPatchGivenOnlyValueWhenApplyMergePatchIsCalledThenItShouldKeepMarked", func(t *testing.T) {
+		todo := &model.Todo{ID: 1, Value: "buy some milk", Marked: 1}
+
+		actual, err := applyMergePatch(todo, []byte(`{"value":"buy some oat milk"}`))
+
+		s.NoError(err)
+		s.Equal(&model.Todo{ID: 1, Value: "buy some oat milk", Marked: 1}, actual)
+	})
+
+	s.T().Run("TestPatchGivenEmptyObjectWhenApplyMergePatchIsCalledThenItShouldReturnTheTodoUnchanged", func(t *testing.T) {
+		actual, err := applyMergePatch(s.todo, []byte(`{}`))
+
+		s.NoError(err)
+		s.Equal(s.todo, actual)
+	})
+
+	s.T().Run("TestPatchGivenSameIdWhenApplyMergePatchIsCalledThenItShouldBeAccepted", func(t *testing.T) {
+		actual, err := applyMergePatch(s.todo, []byte(`{"id":1,"marked":1}`))
+
+		s.NoError(err)
+		s.Equal(1, actual.Marked)
+	})
+
+	s.T().Run("TestPatchGivenNullValueWhenApplyMergePatchIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		_, err := applyMergePatch(s.todo, []byte(`{"value":null}`))
+
+		s.ErrorIs(err, apperr.ErrValidation)
+		s.EqualError(err, "value: can not be removed")
+	})
+
+	s.T().Run("TestPatchGivenDifferentIdWhenApplyMergePatchIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		_, err := applyMergePatch(s.todo, []byte(`{"id":2}`))
+
+		s.ErrorIs(err, apperr.ErrValidation)
+	})
+
+	s.T().Run("TestPatchGivenUnknownMemberWhenApplyMergePatchIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		_, err := applyMergePatch(s.todo, []byte(`{"title":"buy some milk"}`))
+
+		s.ErrorIs(err, apperr.ErrValidation)
+	})
+
+	s.T().Run("TestPatchGivenNonObjectDocumentWhenApplyMergePatchIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		for _, patch := range []string{`[]`, `"marked"`, `null`} {
+			_, err := applyMergePatch(s.todo, []byte(patch))
+
+			s.ErrorIs(err, apperr.ErrValidation, patch)
+		}
+	})
+
+	s.T().Run("TestPatchGivenMalformedJSONWhenApplyMergePatchIsCalledThenItShouldReturnAnInvalidJSONError", func(t *testing.T) {
+		_, err := applyMergePatch(s.todo, []byte(`{`))
+
+		s.ErrorIs(err, apperr.ErrInvalidJSON)
+	})
+
+	s.T().Run("TestPatchGivenOnlyMarkedWhenApplyMergePatchIsCalledThenTheStoredStatusShouldBeDropped", func(t *testing.T) {
+		todo := &model.Todo{ID: 1, Value: "buy some milk", Status: model.StatusInProgress}
+
+		actual, err := applyMergePatch(todo, []byte(`{"marked":1}`))
+
+		s.NoError(err)
+		s.Equal(&model.Todo{ID: 1, Value: "buy some milk", Marked: 1}, actual)
+	})
+
+	s.T().Run("TestPatchGivenOnlyStatusWhenApplyMergePatchIsCalledThenTheStoredMarkedShouldBeDropped", func(t *testing.T) {
+		todo := &model.Todo{ID: 1, Value: "buy some milk", Status: model.StatusDone, Marked: 1}
+
+		actual, err := applyMergePatch(todo, []byte(`{"status":"open"}`))
+
+		s.NoError(err)
+		s.Equal(&model.Todo{ID: 1, Value: "buy some milk", Status: model.StatusOpen}, actual)
+	})
+
+	s.T().Run("TestPatchGivenNullStatusWhenApplyMergePatchIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		_, err := applyMergePatch(s.todo, []byte(`{"status":null}`))
+
+		s.EqualError(err, "status: can not be removed")
+	})
+
+	s.T().Run("TestPatchGivenPatchWhenApplyMergePatchIsCalledThenTheOriginalTodoShouldNotChange", func(t *testing.T) {
+		_, err := applyMergePatch(s.todo, []byte(`{"value":"changed","marked":1}`))
+
+		s.NoError(err)
+		s.Equal(&model.Todo{ID: 1, Value: "buy some milk", Marked: 0}, s.todo)
+	})
+}
@@ -1,52 +1,222 @@
 package todo
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
 
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
 	"github.com/tarkanaciksoz/api-todo-app/internal/model"
 )
 
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+// TodoService scopes every method to the authenticated caller found in the
+// context. Todos of other users are reported as not found so their existence
+// is not revealed.
+//
+// Updates are computed from the stored todo and fail with ErrConflict when
+// another request changed it meanwhile, so none of them is lost.
+//
+// It enforces the status workflow on every write and maintains the timestamps
+// of todos: CreatedAt is set once, UpdatedAt on every write and CompletedAt
+// when a todo gets done. CompletedAt is kept when a done todo gets archived
+// and cleared when it gets reopened. Times are stored in UTC to the second.
 type TodoService struct {
-	L  *log.Logger
-	DB DB
+	L   *slog.Logger
+	DB  DB
+	now func() time.Time
 }
 
 type Service interface {
-	Get(id int) (*model.Todo, error)
-	List() []*model.Todo
-	Create(t *model.Todo) (*model.Todo, error)
-	Mark(t *model.Todo) (*model.Todo, error)
-	Delete(id int) error
-	Log(logMessage string)
+	Get(ctx context.Context, id int) (*model.Todo, error)
+	List(ctx context.Context, opts model.ListOptions) ([]*model.Todo, model.Pagination, error)
+	Create(ctx context.Context, t *model.Todo) (*model.Todo, error)
+	Mark(ctx context.Context, t *model.Todo) (*model.Todo, error)
+	Patch(ctx context.Context, id int, patch []byte) (*model.Todo, error)
+	Transition(ctx context.Context, id int, status string) (*model.Todo, error)
+	Delete(ctx context.Context, id int) error
+	Log(ctx context.Context, level slog.Level, msg string, args ...interface{})
 }
 
-func NewTodoService(l *log.Logger, db DB) Service {
+func NewTodoService(l *slog.Logger, db DB) Service {
 	return TodoService{
-		L:  l,
-		DB: db,
+		L:   l,
+		DB:  db,
+		now: time.Now,
+	}
+}
+
+func (ts TodoService) Get(ctx context.Context, id int) (*model.Todo, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.DB.Get(ctx, owner, id)
+}
+
+// List returns a page of todos. Requests without a limit get DefaultListLimit
+// items and no request can ask for more than MaxListLimit.
+func (ts TodoService) List(ctx context.Context, opts model.ListOptions) ([]*model.Todo, model.Pagination, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, model.Pagination{}, err
+	}
+
+	if opts.Limit == 0 {
+		opts.Limit = DefaultListLimit
+	}
+	if opts.Limit > MaxListLimit {
+		return nil, model.Pagination{}, &apperr.ValidationError{Field: "limit", Message: "must be at most " + strconv.Itoa(MaxListLimit)}
+	}
+
+	return ts.DB.List(ctx, owner, opts)
+}
+
+func (ts TodoService) Create(ctx context.Context, todo *model.Todo) (*model.Todo, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateTodo(todo); err != nil {
+		return nil, err
+	}
+	if err := resolveStatus(nil, todo); err != nil {
+		return nil, err
+	}
+	todo.OwnerID = owner
+	ts.stamp(nil, todo)
+
+	return ts.DB.Create(ctx, todo)
+}
+
+// Mark replaces every field of the todo but its timestamps. Status changes
+// must follow the workflow.
+func (ts TodoService) Mark(ctx context.Context, todo *model.Todo) (*model.Todo, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateTodo(todo); err != nil {
+		return nil, err
+	}
+
+	stored, err := ts.DB.Get(ctx, owner, todo.ID)
+	if err != nil {
+		return nil, err
 	}
+	if err := resolveStatus(stored, todo); err != nil {
+		return nil, err
+	}
+	todo.OwnerID = owner
+	ts.stamp(stored, todo)
+
+	return ts.DB.Mark(ctx, todo, stored.Version)
 }
 
-func (ts TodoService) Get(id int) (*model.Todo, error) {
-	return ts.DB.Get(id)
+// Patch applies a JSON Merge Patch document to the todo with the given id.
+func (ts TodoService) Patch(ctx context.Context, id int, patch []byte) (*model.Todo, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := ts.DB.Get(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := applyMergePatch(stored, patch)
+	if err != nil {
+		return nil, err
+	}
+	todo.OwnerID = owner
+
+	if err := validateTodo(todo); err != nil {
+		return nil, err
+	}
+	if err := resolveStatus(stored, todo); err != nil {
+		return nil, err
+	}
+	ts.stamp(stored, todo)
+
+	return ts.DB.Mark(ctx, todo, stored.Version)
 }
 
-func (ts TodoService) List() []*model.Todo {
-	return ts.DB.List()
+// Transition moves the todo with the given id to status.
+func (ts TodoService) Transition(ctx context.Context, id int, status string) (*model.Todo, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !validStatus(status) {
+		return nil, &apperr.ValidationError{Field: "status", Message: statusMessage}
+	}
+
+	stored, err := ts.DB.Get(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := *stored
+	todo.Status = status
+	if err := resolveStatus(stored, &todo); err != nil {
+		return nil, err
+	}
+	ts.stamp(stored, &todo)
+
+	return ts.DB.Mark(ctx, &todo, stored.Version)
 }
 
-func (ts TodoService) Create(todo *model.Todo) (*model.Todo, error) {
-	return ts.DB.Create(todo)
+func (ts TodoService) Delete(ctx context.Context, id int) error {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ts.DB.Delete(ctx, owner, id)
 }
 
-func (ts TodoService) Mark(todo *model.Todo) (*model.Todo, error) {
-	return ts.DB.Mark(todo)
+// Log lets the todo handler log through the logger of the service, so that
+// its records carry the request ID of ctx like those of the service.
+func (ts TodoService) Log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	ts.L.Log(ctx, level, msg, args...)
 }
 
-func (ts TodoService) Delete(id int) error {
-	return ts.DB.Delete(id)
+// stamp sets the timestamps of todo, the new state of stored. stored is nil
+// for todos being created.
+func (ts TodoService) stamp(stored *model.Todo, todo *model.Todo) {
+	now := ts.now().UTC().Truncate(time.Second)
+
+	todo.CreatedAt, todo.UpdatedAt, todo.CompletedAt = now, now, nil
+	if stored != nil {
+		todo.CreatedAt = stored.CreatedAt
+	}
+
+	switch {
+	case todo.Status == model.StatusDone && stored != nil && stored.Status == model.StatusDone:
+		todo.CompletedAt = stored.CompletedAt
+	case todo.Status == model.StatusDone:
+		todo.CompletedAt = &now
+	case todo.Status == model.StatusArchived && stored != nil:
+		todo.CompletedAt = stored.CompletedAt
+	}
 }
 
-func (ts TodoService) Log(logMessage string) {
-	ts.L.Println(logMessage)
+func ownerID(ctx context.Context) (int, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return 0, apperr.ErrUnauthenticated
+	}
+
+	return identity.UserID, nil
 }
@@ -1,13 +1,19 @@
 package todo
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
 	mockService "github.com/tarkanaciksoz/api-todo-app/internal/mocks"
 	"github.com/tarkanaciksoz/api-todo-app/internal/model"
 )
@@ -43,8 +49,8 @@ func (s *ServiceSuite) TestServiceGivenWhenServiceGetIsCalled() {
 			Marked: 0,
 		}
 
-		s.mockService.EXPECT().Get(1).Return(expectedResponse, nil).Times(1)
-		actualResponse, actualErr := s.mockService.Get(1)
+		s.mockService.EXPECT().Get(gomock.Any(), 1).Return(expectedResponse, nil).Times(1)
+		actualResponse, actualErr := s.mockService.Get(context.Background(), 1)
 
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
@@ -54,8 +60,8 @@ func (s *ServiceSuite) TestServiceGivenWhenServiceGetIsCalled() {
 		expectedResponse := &model.Todo{}
 		expectedErr := errors.New("no todo found with id:1")
 
-		s.mockService.EXPECT().Get(1).Return(expectedResponse, expectedErr).Times(1)
-		actualResponse, actualErr := s.mockService.Get(1)
+		s.mockService.EXPECT().Get(gomock.Any(), 1).Return(expectedResponse, expectedErr).Times(1)
+		actualResponse, actualErr := s.mockService.Get(context.Background(), 1)
 
 		s.EqualError(actualErr, expectedErr.Error())
 		s.Equal(expectedResponse, actualResponse)
@@ -65,9 +71,10 @@ func (s *ServiceSuite) TestServiceGivenWhenServiceGetIsCalled() {
 func (s *ServiceSuite) TestServiceGivenWhenListIsCalled() {
 	s.T().Run("TestServiceGivenEmptyTodoListWhenListIsCalledThenItShouldReturnEmptyTodoList", func(t *testing.T) {
 		expectedResponse := []*model.Todo{}
-		s.mockService.EXPECT().List().Return(expectedResponse).Times(1)
+		s.mockService.EXPECT().List(gomock.Any(), model.ListOptions{}).Return(expectedResponse, model.Pagination{}, nil).Times(1)
 
-		actualResponse := s.mockService.List()
+		actualResponse, _, actualErr := s.mockService.List(context.Background(), model.ListOptions{})
+		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
 	})
 
@@ -84,9 +91,10 @@ func (s *ServiceSuite) TestServiceGivenWhenListIsCalled() {
 				Marked: 0,
 			},
 		}
-		s.mockService.EXPECT().List().Return(expectedResponse).Times(1)
+		s.mockService.EXPECT().List(gomock.Any(), model.ListOptions{}).Return(expectedResponse, model.Pagination{}, nil).Times(1)
 
-		actualResponse := s.mockService.List()
+		actualResponse, _, actualErr := s.mockService.List(context.Background(), model.ListOptions{})
+		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
 	})
 }
@@ -103,9 +111,9 @@ func (s *ServiceSuite) TestServiceGivenWhenCreateIsCalled() {
 			Value:  "buy some milk",
 			Marked: 0,
 		}
-		s.mockService.EXPECT().Create(createTodoRequest).Return(expectedResponse, nil).Times(1)
+		s.mockService.EXPECT().Create(gomock.Any(), createTodoRequest).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockService.Create(createTodoRequest)
+		actualResponse, actualErr := s.mockService.Create(context.Background(), createTodoRequest)
 
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
@@ -122,9 +130,9 @@ func (s *ServiceSuite) TestServiceGivenWhenCreateIsCalled() {
 			Value:  "buy some milk",
 			Marked: 0,
 		}
-		s.mockService.EXPECT().Create(createTodoRequest).Return(expectedResponse, nil).Times(1)
+		s.mockService.EXPECT().Create(gomock.Any(), createTodoRequest).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockService.Create(createTodoRequest)
+		actualResponse, actualErr := s.mockService.Create(context.Background(), createTodoRequest)
 
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
@@ -142,9 +150,9 @@ func (s *ServiceSuite) TestServiceGivenWhenMarkIsCalled() {
 			Value:  "buy some milk",
 			Marked: 1,
 		}
-		s.mockService.EXPECT().Mark(markTodoRequest).Return(expectedResponse, nil).Times(1)
+		s.mockService.EXPECT().Mark(gomock.Any(), markTodoRequest).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockService.Mark(markTodoRequest)
+		actualResponse, actualErr := s.mockService.Mark(context.Background(), markTodoRequest)
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
 	})
@@ -157,8 +165,8 @@ func (s *ServiceSuite) TestServiceGivenWhenMarkIsCalled() {
 		expectedResponse := &model.Todo{}
 		expectedErr := errors.New("no todo found with id:1")
 
-		s.mockService.EXPECT().Mark(markTodoRequest).Return(expectedResponse, expectedErr).Times(1)
-		actualResponse, actualErr := s.mockService.Mark(markTodoRequest)
+		s.mockService.EXPECT().Mark(gomock.Any(), markTodoRequest).Return(expectedResponse, expectedErr).Times(1)
+		actualResponse, actualErr := s.mockService.Mark(context.Background(), markTodoRequest)
 
 		s.EqualError(actualErr, expectedErr.Error())
 		s.Equal(expectedResponse, actualResponse)
@@ -174,9 +182,9 @@ func (s *ServiceSuite) TestServiceGivenWhenMarkIsCalled() {
 			Value:  "buy some milk",
 			Marked: 0,
 		}
-		s.mockService.EXPECT().Mark(markTodoRequest).Return(expectedResponse, nil).Times(1)
+		s.mockService.EXPECT().Mark(gomock.Any(), markTodoRequest).Return(expectedResponse, nil).Times(1)
 
-		actualResponse, actualErr := s.mockService.Mark(markTodoRequest)
+		actualResponse, actualErr := s.mockService.Mark(context.Background(), markTodoRequest)
 		s.NoError(actualErr)
 		s.Equal(expectedResponse, actualResponse)
 	})
@@ -189,8 +197,8 @@ func (s *ServiceSuite) TestServiceGivenWhenMarkIsCalled() {
 		expectedResponse := &model.Todo{}
 		expectedErr := errors.New("no todo found with id:1")
 
-		s.mockService.EXPECT().Mark(markTodoRequest).Return(expectedResponse, expectedErr).Times(1)
-		actualResponse, actualErr := s.mockService.Mark(markTodoRequest)
+		s.mockService.EXPECT().Mark(gomock.Any(), markTodoRequest).Return(expectedResponse, expectedErr).Times(1)
+		actualResponse, actualErr := s.mockService.Mark(context.Background(), markTodoRequest)
 
 		s.EqualError(actualErr, expectedErr.Error())
 		s.Equal(expectedResponse, actualResponse)
@@ -199,17 +207,176 @@ func (s *ServiceSuite) TestServiceGivenWhenMarkIsCalled() {
 
 func (s *ServiceSuite) TestServiceGivenWhenDeleteIsCalled() {
 	s.T().Run("TestServiceGivenExistingIdWhenDeleteIsCalledThenItShouldReturnNilError", func(t *testing.T) {
-		s.mockService.EXPECT().Delete(1).Return(nil).Times(1)
-		actualErr := s.mockService.Delete(1)
+		s.mockService.EXPECT().Delete(gomock.Any(), 1).Return(nil).Times(1)
+		actualErr := s.mockService.Delete(context.Background(), 1)
 		s.NoError(actualErr)
 	})
 
 	s.T().Run("TestServiceGivenUnExistingIdWhenDeleteIsCalledThenItShouldReturnAnError", func(t *testing.T) {
 		expectedError := errors.New("no todo found with id:1")
 
-		s.mockService.EXPECT().Delete(1).Return(expectedError).Times(1)
-		actualErr := s.mockService.Delete(1)
+		s.mockService.EXPECT().Delete(gomock.Any(), 1).Return(expectedError).Times(1)
+		actualErr := s.mockService.Delete(context.Background(), 1)
 
 		s.EqualError(actualErr, expectedError.Error())
 	})
 }
+
+func (s *ServiceSuite) TestServiceGivenWhenConcurrentWritesHappen() {
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{UserID: 1})
+	stored := &model.Todo{ID: 1, Value: "buy some milk", Status: model.StatusOpen, OwnerID: 1, Version: 3}
+
+	s.T().Run("TestServiceGivenTodoChangedMeanwhileWhenPatchIsCalledThenItShouldReturnErrConflict", func(t *testing.T) {
+		db := mockService.NewMockDB(s.ctrl)
+		service := TodoService{L: logging.Discard(), DB: db, now: time.Now}
+
+		db.EXPECT().Get(gomock.Any(), 1, 1).Return(stored, nil).Times(1)
+		db.EXPECT().Mark(gomock.Any(), gomock.Any(), 3).Return(nil, errConflict(1)).Times(1)
+
+		_, err := service.Patch(ctx, 1, []byte(`{"value":"buy some oat milk"}`))
+
+		s.ErrorIs(err, ErrConflict)
+		s.Equal(http.StatusConflict, StatusCode(err))
+	})
+
+	s.T().Run("TestServiceGivenTodoChangedMeanwhileWhenMarkIsCalledThenItShouldReturnErrConflict", func(t *testing.T) {
+		db := mockService.NewMockDB(s.ctrl)
+		service := TodoService{L: logging.Discard(), DB: db, now: time.Now}
+
+		db.EXPECT().Get(gomock.Any(), 1, 1).Return(stored, nil).Times(1)
+		db.EXPECT().Mark(gomock.Any(), gomock.Any(), 3).Return(nil, errConflict(1)).Times(1)
+
+		_, err := service.Mark(ctx, &model.Todo{ID: 1, Value: "buy some oat milk"})
+
+		s.ErrorIs(err, ErrConflict)
+	})
+}
+
+func (s *ServiceSuite) TestServiceGivenWhenTimestampsAreMaintained() {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := TodoService{L: logging.Discard(), DB: NewDB(), now: func() time.Time { return now }}
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{UserID: 1})
+	created := now
+
+	s.T().Run("TestServiceGivenNewTodoWithClientTimestampsWhenCreateIsCalledThenTheyShouldBeReplaced", func(t *testing.T) {
+		due := time.Date(2024, 1, 2, 9, 30, 0, 500, time.FixedZone("UTC+3", 3*60*60))
+		todo, err := service.Create(ctx, &model.Todo{
+			Value:     "buy some milk",
+			DueDate:   &due,
+			Priority:  model.PriorityHigh,
+			CreatedAt: now.Add(-time.Hour),
+		})
+
+		s.NoError(err)
+		s.Equal(now, todo.CreatedAt)
+		s.Equal(now, todo.UpdatedAt)
+		s.Nil(todo.CompletedAt)
+		s.Equal(time.Date(2024, 1, 2, 6, 30, 0, 0, time.UTC), *todo.DueDate)
+	})
+
+	s.T().Run("TestServiceGivenUnMarkedTodoWhenItIsMarkedThenItShouldBeCompletedNow", func(t *testing.T) {
+		now = now.Add(time.Hour)
+
+		todo, err := service.Mark(ctx, &model.Todo{ID: 1, Value: "buy some milk", Marked: 1})
+
+		s.NoError(err)
+		s.Equal(created, todo.CreatedAt)
+		s.Equal(now, todo.UpdatedAt)
+		s.Equal(now, *todo.CompletedAt)
+	})
+
+	s.T().Run("TestServiceGivenMarkedTodoWhenItIsPatchedThenItShouldKeepItsCompletionTime", func(t *testing.T) {
+		completed := now
+		now = now.Add(time.Hour)
+
+		todo, err := service.Patch(ctx, 1, []byte(`{"description":"the oat one","completed_at":null}`))
+
+		s.NoError(err)
+		s.Equal("the oat one", todo.Description)
+		s.Equal(now, todo.UpdatedAt)
+		s.Equal(completed, *todo.CompletedAt)
+	})
+
+	s.T().Run("TestServiceGivenMarkedTodoWhenItIsUnMarkedThenItsCompletionTimeShouldBeCleared", func(t *testing.T) {
+		todo, err := service.Patch(ctx, 1, []byte(`{"marked":0}`))
+
+		s.NoError(err)
+		s.Equal(created, todo.CreatedAt)
+		s.Nil(todo.CompletedAt)
+	})
+
+	s.T().Run("TestServiceGivenUnExistingTodoWhenMarkIsCalledThenItShouldReturnErrNotFound", func(t *testing.T) {
+		_, err := service.Mark(ctx, &model.Todo{ID: 42, Value: "buy some milk", Marked: 1})
+
+		s.ErrorIs(err, ErrNotFound)
+	})
+}
+
+func (s *ServiceSuite) TestServiceGivenWhenTransitionIsCalled() {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := TodoService{L: logging.Discard(), DB: NewDB(), now: func() time.Time { return now }}
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{UserID: 1})
+
+	created, err := service.Create(ctx, &model.Todo{Value: "buy some milk"})
+	s.NoError(err)
+	s.Equal(model.StatusOpen, created.Status)
+
+	s.T().Run("TestServiceGivenAllowedStatusWhenTransitionIsCalledThenTheTodoShouldMove", func(t *testing.T) {
+		for _, status := range []string{model.StatusInProgress, model.StatusBlocked, model.StatusDone} {
+			todo, err := service.Transition(ctx, created.ID, status)
+
+			s.NoError(err)
+			s.Equal(status, todo.Status)
+			s.Equal(model.MarkedFor(status), todo.Marked)
+		}
+	})
+
+	s.T().Run("TestServiceGivenDoneTodoWhenItIsArchivedThenItShouldKeepItsCompletionTime", func(t *testing.T) {
+		now = now.Add(time.Hour)
+
+		todo, err := service.Transition(ctx, created.ID, model.StatusArchived)
+
+		s.NoError(err)
+		s.Equal(now.Add(-time.Hour), *todo.CompletedAt)
+		s.Equal(now, todo.UpdatedAt)
+	})
+
+	s.T().Run("TestServiceGivenForbiddenStatusWhenTransitionIsCalledThenItShouldReturnErrInvalidTransitionAndKeepTheTodo", func(t *testing.T) {
+		_, err := service.Transition(ctx, created.ID, model.StatusInProgress)
+		s.ErrorIs(err, ErrInvalidTransition)
+
+		_, err = service.Mark(ctx, &model.Todo{ID: created.ID, Value: "buy some milk", Marked: 1})
+		s.ErrorIs(err, ErrInvalidTransition)
+
+		stored, err := service.Get(ctx, created.ID)
+		s.NoError(err)
+		s.Equal(model.StatusArchived, stored.Status)
+	})
+
+	s.T().Run("TestServiceGivenUnknownStatusWhenTransitionIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		_, err := service.Transition(ctx, created.ID, "finished")
+
+		s.ErrorIs(err, apperr.ErrValidation)
+	})
+
+	s.T().Run("TestServiceGivenArchivedTodoWhenItIsReopenedThenItsCompletionTimeShouldBeCleared", func(t *testing.T) {
+		todo, err := service.Patch(ctx, created.ID, []byte(`{"status":"open"}`))
+
+		s.NoError(err)
+		s.Equal(0, todo.Marked)
+		s.Nil(todo.CompletedAt)
+	})
+
+	s.T().Run("TestServiceGivenTodoChangedMeanwhileWhenTransitionIsCalledThenItShouldReturnErrConflict", func(t *testing.T) {
+		db := mockService.NewMockDB(s.ctrl)
+		service := TodoService{L: logging.Discard(), DB: db, now: time.Now}
+		stored := &model.Todo{ID: 1, Value: "buy some milk", Status: model.StatusOpen, OwnerID: 1, Version: 3}
+
+		db.EXPECT().Get(gomock.Any(), 1, 1).Return(stored, nil).Times(1)
+		db.EXPECT().Mark(gomock.Any(), gomock.Any(), 3).Return(nil, errConflict(1)).Times(1)
+
+		_, err := service.Transition(ctx, 1, model.StatusDone)
+
+		s.ErrorIs(err, ErrConflict)
+	})
+}
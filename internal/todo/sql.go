@@ -0,0 +1,336 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/database"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+const todoColumns = "id, value, description, due_date, priority, status, created_at, updated_at, completed_at, owner_id, version"
+
+// markedColumn derives the legacy marked flag from the status column.
+const markedColumn = "CASE WHEN status = 'done' THEN 1 ELSE 0 END"
+
+// SQLStore is a DB implementation backed by database/sql. The same queries
+// serve SQLite and Postgres; placeholders are rebound per driver. IDs come
+// from AUTOINCREMENT/BIGSERIAL columns so they are never reused after Delete.
+// Queries are logged with their duration at debug level when L is set.
+type SQLStore struct {
+	DB     *sql.DB
+	Driver string
+	L      *slog.Logger
+}
+
+// NewSQLite opens (or creates) the SQLite database file at path.
+func NewSQLite(path string) (*SQLStore, error) {
+	return openSQLStore(database.DriverSQLite, path)
+}
+
+// NewPostgres connects to the Postgres server described by dsn.
+func NewPostgres(dsn string) (*SQLStore, error) {
+	return openSQLStore(database.DriverPostgres, dsn)
+}
+
+func openSQLStore(driver string, dsn string) (*SQLStore, error) {
+	db, err := database.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSQLStore(db, driver), nil
+}
+
+// NewSQLStore wraps an already opened connection pool.
+func NewSQLStore(db *sql.DB, driver string) *SQLStore {
+	return &SQLStore{DB: db, Driver: driver}
+}
+
+// Migrate brings the schema up to date with the embedded migrations.
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	return database.Migrate(ctx, s.DB, s.Driver)
+}
+
+func (s *SQLStore) Get(ctx context.Context, ownerID int, id int) (*model.Todo, error) {
+	if !(id > 0) {
+		return nil, ErrInvalidID
+	}
+
+	todo, err := scanTodo(s.queryRow(ctx, "SELECT "+todoColumns+" FROM todos WHERE id = ? AND owner_id = ?", id, ownerID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+func (s *SQLStore) List(ctx context.Context, ownerID int, opts model.ListOptions) ([]*model.Todo, model.Pagination, error) {
+	opts, err := normalizeListOptions(opts)
+	if err != nil {
+		return nil, model.Pagination{}, err
+	}
+
+	after, err := decodeCursor(opts)
+	if err != nil {
+		return nil, model.Pagination{}, err
+	}
+
+	where := []string{"owner_id = ?"}
+	args := []interface{}{ownerID}
+
+	if opts.Marked != nil {
+		where = append(where, markedColumn+" = ?")
+		args = append(args, *opts.Marked)
+	}
+
+	if opts.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, opts.Status)
+	}
+
+	if opts.Contains != "" {
+		where = append(where, s.foldedValue()+" LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+likeEscaper.Replace(foldASCII(opts.Contains))+"%")
+	}
+
+	if opts.Priority != "" {
+		where = append(where, "priority = ?")
+		args = append(args, opts.Priority)
+	}
+
+	// Due dates are compared in UTC: SQLite stores them as text.
+	if opts.DueBefore != nil {
+		where = append(where, "due_date < ?")
+		args = append(args, opts.DueBefore.UTC())
+	}
+
+	if opts.DueAfter != nil {
+		where = append(where, "due_date > ?")
+		args = append(args, opts.DueAfter.UTC())
+	}
+
+	column, direction, comparison := s.sortColumn(opts.Sort), "ASC", ">"
+	if opts.Order == model.OrderDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if after != nil {
+		switch opts.Sort {
+		case model.SortByID:
+			where = append(where, "id "+comparison+" ?")
+			args = append(args, after.ID)
+		case model.SortByValue:
+			where = append(where, "("+column+" "+comparison+" ? OR ("+column+" = ? AND id "+comparison+" ?))")
+			args = append(args, after.Value, after.Value, after.ID)
+		case model.SortByMarked:
+			where = append(where, "("+column+" "+comparison+" ? OR ("+column+" = ? AND id "+comparison+" ?))")
+			args = append(args, after.Marked, after.Marked, after.ID)
+		}
+	}
+
+	query := "SELECT " + todoColumns + " FROM todos WHERE " + strings.Join(where, " AND ")
+	query += " ORDER BY " + column + " " + direction
+	if opts.Sort != model.SortByID {
+		query += ", id " + direction
+	}
+	if opts.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, opts.Limit+1)
+	}
+
+	start := time.Now()
+	rows, err := s.DB.QueryContext(ctx, database.Rebind(s.Driver, query), args...)
+	s.logQuery(ctx, query, start)
+	if err != nil {
+		return nil, model.Pagination{}, err
+	}
+	defer rows.Close()
+
+	todos := []*model.Todo{}
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, model.Pagination{}, err
+		}
+		todos = append(todos, todo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.Pagination{}, err
+	}
+
+	todos, page := paginate(opts, todos)
+	return todos, page, nil
+}
+
+func (s *SQLStore) Create(ctx context.Context, todo *model.Todo) (*model.Todo, error) {
+	return scanTodo(s.queryRow(ctx, "INSERT INTO todos (value, description, due_date, priority, status, created_at, updated_at, completed_at, owner_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING "+todoColumns,
+		todo.Value, todo.Description, nullTime(todo.DueDate), todo.Priority, todo.Status, todo.CreatedAt.UTC(), todo.UpdatedAt.UTC(), nullTime(todo.CompletedAt), todo.OwnerID))
+}
+
+func (s *SQLStore) Mark(ctx context.Context, todo *model.Todo, version int) (*model.Todo, error) {
+	updated, err := scanTodo(s.queryRow(ctx, "UPDATE todos SET value = ?, description = ?, due_date = ?, priority = ?, status = ?, created_at = ?, updated_at = ?, completed_at = ?, version = version + 1 WHERE id = ? AND owner_id = ? AND version = ? RETURNING "+todoColumns,
+		todo.Value, todo.Description, nullTime(todo.DueDate), todo.Priority, todo.Status, todo.CreatedAt.UTC(), todo.UpdatedAt.UTC(), nullTime(todo.CompletedAt), todo.ID, todo.OwnerID, version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, s.missed(ctx, todo.OwnerID, todo.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// missed tells why a conditional update matched no row: the todo is either
+// gone or was changed since it was read.
+func (s *SQLStore) missed(ctx context.Context, ownerID int, id int) error {
+	var found int
+	err := s.queryRow(ctx, "SELECT 1 FROM todos WHERE id = ? AND owner_id = ?", id, ownerID).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errNotFound(id)
+	}
+	if err != nil {
+		return err
+	}
+
+	return errConflict(id)
+}
+
+func (s *SQLStore) Delete(ctx context.Context, ownerID int, id int) error {
+	res, err := s.exec(ctx, "DELETE FROM todos WHERE id = ? AND owner_id = ?", id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errNotFound(id)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) Count(ctx context.Context) (int, error) {
+	var count int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM todos").Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ClaimOrphans gives the todos created before accounts existed, stored with
+// owner 0 by migration 0003, to ownerID and returns how many there were.
+func (s *SQLStore) ClaimOrphans(ctx context.Context, ownerID int) (int, error) {
+	res, err := s.exec(ctx, "UPDATE todos SET owner_id = ?, version = version + 1 WHERE owner_id = 0", ownerID)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
+
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.DB.PingContext(ctx)
+}
+
+func (s *SQLStore) Close() error {
+	return s.DB.Close()
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTodo reads a row selected with todoColumns. Times are returned in UTC
+// whatever the driver hands back.
+func scanTodo(row rowScanner) (*model.Todo, error) {
+	todo := &model.Todo{}
+	var dueDate, completedAt sql.NullTime
+	if err := row.Scan(&todo.ID, &todo.Value, &todo.Description, &dueDate, &todo.Priority, &todo.Status,
+		&todo.CreatedAt, &todo.UpdatedAt, &completedAt, &todo.OwnerID, &todo.Version); err != nil {
+		return nil, err
+	}
+
+	todo.Marked = model.MarkedFor(todo.Status)
+	todo.DueDate = utcTime(dueDate)
+	todo.CreatedAt = todo.CreatedAt.UTC()
+	todo.UpdatedAt = todo.UpdatedAt.UTC()
+	todo.CompletedAt = utcTime(completedAt)
+
+	return todo, nil
+}
+
+// nullTime converts an optional time into a query argument.
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func utcTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	utc := t.Time.UTC()
+	return &utc
+}
+
+// sortColumn returns the ORDER BY expression for a sort field. Postgres sorts
+// text by locale by default, so values are compared bytewise like Memory does.
+func (s *SQLStore) sortColumn(sort string) string {
+	switch {
+	case sort == model.SortByValue && s.Driver == database.DriverPostgres:
+		return `value COLLATE "C"`
+	case sort == model.SortByMarked:
+		return markedColumn
+	}
+
+	return sort
+}
+
+// foldedValue returns the value column with its ASCII letters lowercased, like
+// foldASCII does. SQLite's LOWER only folds ASCII while Postgres' follows the
+// locale, so Postgres maps the letters itself.
+func (s *SQLStore) foldedValue() string {
+	if s.Driver == database.DriverPostgres {
+		return "TRANSLATE(value, 'ABCDEFGHIJKLMNOPQRSTUVWXYZ', 'abcdefghijklmnopqrstuvwxyz')"
+	}
+
+	return "LOWER(value)"
+}
+
+func (s *SQLStore) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer s.logQuery(ctx, query, time.Now())
+	return s.DB.QueryRowContext(ctx, database.Rebind(s.Driver, query), args...)
+}
+
+func (s *SQLStore) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer s.logQuery(ctx, query, time.Now())
+	return s.DB.ExecContext(ctx, database.Rebind(s.Driver, query), args...)
+}
+
+func (s *SQLStore) logQuery(ctx context.Context, query string, start time.Time) {
+	if s.L != nil {
+		s.L.DebugContext(ctx, "query executed", "driver", s.Driver, "query", query, "duration", time.Since(start))
+	}
+}
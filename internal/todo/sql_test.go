@@ -0,0 +1,107 @@
+package todo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/database"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// SQLSuite covers SQLStore specifics on top of the shared conformance suite.
+// Set POSTGRES_TEST_DSN (see the test-postgres target in the Makefile) to run
+// it against Postgres as well.
+type SQLSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	open func() (*SQLStore, error)
+	db   *SQLStore
+}
+
+func TestSQLiteSuite(t *testing.T) {
+	var path string
+	suite.Run(t, &SQLSuite{open: func() (*SQLStore, error) {
+		if path == "" {
+			path = filepath.Join(t.TempDir(), "todo.db")
+		}
+		return NewSQLite(path)
+	}})
+}
+
+func TestPostgresSuite(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	suite.Run(t, &SQLSuite{open: func() (*SQLStore, error) {
+		return NewPostgres(dsn)
+	}})
+}
+
+func (s *SQLSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+
+	db, err := s.open()
+	s.NoError(err)
+	s.NoError(db.Migrate(context.Background()))
+	s.db = db
+
+	if db.Driver == database.DriverPostgres {
+		_, err = db.DB.Exec("TRUNCATE todos RESTART IDENTITY")
+		s.NoError(err)
+	} else {
+		_, err = db.DB.Exec("DELETE FROM todos; DELETE FROM sqlite_sequence WHERE name = 'todos'")
+		s.NoError(err)
+	}
+}
+
+func (s *SQLSuite) TearDownTest() {
+	s.db.Close()
+}
+
+func (s *SQLSuite) TestSQLGivenReopenedDatabase() {
+	s.T().Run("TestSQLGivenStoredTodosWhenDatabaseIsReopenedThenTheTodosShouldStillExist", func(t *testing.T) {
+		created, err := s.db.Create(context.Background(), &model.Todo{Value: "buy some milk"})
+		s.NoError(err)
+		s.NoError(s.db.Close())
+
+		s.db, err = s.open()
+		s.NoError(err)
+		s.NoError(s.db.Migrate(context.Background()))
+
+		actualResponse, actualErr := s.db.Get(context.Background(), created.OwnerID, created.ID)
+		s.NoError(actualErr)
+		s.Equal("buy some milk", actualResponse.Value)
+	})
+}
+
+func (s *SQLSuite) TestSQLGivenOrphanedTodos() {
+	s.T().Run("TestSQLGivenTodosWithoutOwnerWhenClaimOrphansIsCalledThenOnlyTheyShouldMoveToTheOwner", func(t *testing.T) {
+		orphan, err := s.db.Create(context.Background(), &model.Todo{Value: "buy some milk"})
+		s.NoError(err)
+		owned, err := s.db.Create(context.Background(), &model.Todo{Value: "walk the dog", OwnerID: 2})
+		s.NoError(err)
+
+		claimed, err := s.db.ClaimOrphans(context.Background(), 1)
+		s.NoError(err)
+		s.Equal(1, claimed)
+
+		actualResponse, actualErr := s.db.Get(context.Background(), 1, orphan.ID)
+		s.NoError(actualErr)
+		s.Equal("buy some milk", actualResponse.Value)
+
+		_, actualErr = s.db.Get(context.Background(), 1, owned.ID)
+		s.ErrorIs(actualErr, ErrNotFound)
+
+		claimed, err = s.db.ClaimOrphans(context.Background(), 1)
+		s.NoError(err)
+		s.Zero(claimed)
+	})
+}
@@ -0,0 +1,67 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// transitions lists the statuses each status can move to. Done todos must be
+// reopened before work resumes on them and archived todos can only be
+// reopened.
+var transitions = map[string][]string{
+	model.StatusOpen:       {model.StatusInProgress, model.StatusBlocked, model.StatusDone, model.StatusArchived},
+	model.StatusInProgress: {model.StatusOpen, model.StatusBlocked, model.StatusDone, model.StatusArchived},
+	model.StatusBlocked:    {model.StatusOpen, model.StatusInProgress, model.StatusDone, model.StatusArchived},
+	model.StatusDone:       {model.StatusOpen, model.StatusArchived},
+	model.StatusArchived:   {model.StatusOpen},
+}
+
+const statusMessage = "must be one of open, in_progress, blocked, done, archived"
+
+func validStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// checkTransition reports whether a todo may move from one status to another.
+// Keeping the same status is always allowed.
+func checkTransition(from string, to string) error {
+	if from == to {
+		return nil
+	}
+
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w from %s to %s, allowed: %s", ErrInvalidTransition, from, to, strings.Join(transitions[from], ", "))
+}
+
+// resolveStatus sets the status of todo, the new state of stored, and checks
+// the transition. stored is nil for todos being created. Clients that only
+// send marked get done for 1; for 0 done todos are reopened and others keep
+// their status. Marked is then derived from the status.
+func resolveStatus(stored *model.Todo, todo *model.Todo) error {
+	from := model.StatusOpen
+	if stored != nil {
+		from = stored.Status
+	}
+
+	if todo.Status == "" {
+		switch {
+		case todo.Marked == 1:
+			todo.Status = model.StatusDone
+		case from == model.StatusDone:
+			todo.Status = model.StatusOpen
+		default:
+			todo.Status = from
+		}
+	}
+	todo.Marked = model.MarkedFor(todo.Status)
+
+	return checkTransition(from, todo.Status)
+}
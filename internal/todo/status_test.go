@@ -0,0 +1,92 @@
+package todo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+type StatusSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestStatusSuite(t *testing.T) {
+	suite.Run(t, new(StatusSuite))
+}
+
+func (s *StatusSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+}
+
+func (s *StatusSuite) TestStatusGivenWhenCheckTransitionIsCalled() {
+	s.T().Run("TestStatusGivenAllowedTransitionsWhenCheckTransitionIsCalledThenTheyShouldBeAccepted", func(t *testing.T) {
+		for _, transition := range [][2]string{
+			{model.StatusOpen, model.StatusInProgress},
+			{model.StatusInProgress, model.StatusBlocked},
+			{model.StatusBlocked, model.StatusDone},
+			{model.StatusDone, model.StatusArchived},
+			{model.StatusArchived, model.StatusOpen},
+			{model.StatusDone, model.StatusDone},
+		} {
+			s.NoError(checkTransition(transition[0], transition[1]), transition)
+		}
+	})
+
+	s.T().Run("TestStatusGivenForbiddenTransitionsWhenCheckTransitionIsCalledThenTheyShouldReturnErrInvalidTransition", func(t *testing.T) {
+		for _, transition := range [][2]string{
+			{model.StatusDone, model.StatusInProgress},
+			{model.StatusDone, model.StatusBlocked},
+			{model.StatusArchived, model.StatusDone},
+			{model.StatusArchived, model.StatusInProgress},
+		} {
+			s.ErrorIs(checkTransition(transition[0], transition[1]), ErrInvalidTransition, transition)
+		}
+
+		s.EqualError(checkTransition(model.StatusArchived, model.StatusDone), "todo can not move from archived to done, allowed: open")
+	})
+}
+
+func (s *StatusSuite) TestStatusGivenWhenResolveStatusIsCalled() {
+	stored := func(status string) *model.Todo {
+		return &model.Todo{ID: 1, Status: status, Marked: model.MarkedFor(status)}
+	}
+
+	s.T().Run("TestStatusGivenOnlyMarkedWhenResolveStatusIsCalledThenTheStatusShouldFollowTheFlag", func(t *testing.T) {
+		cases := []struct {
+			stored   *model.Todo
+			marked   int
+			expected string
+		}{
+			{nil, 0, model.StatusOpen},
+			{nil, 1, model.StatusDone},
+			{stored(model.StatusInProgress), 1, model.StatusDone},
+			{stored(model.StatusInProgress), 0, model.StatusInProgress},
+			{stored(model.StatusDone), 0, model.StatusOpen},
+			{stored(model.StatusDone), 1, model.StatusDone},
+			{stored(model.StatusArchived), 0, model.StatusArchived},
+		}
+
+		for _, c := range cases {
+			todo := &model.Todo{Marked: c.marked}
+
+			s.NoError(resolveStatus(c.stored, todo))
+			s.Equal(c.expected, todo.Status)
+			s.Equal(c.marked, todo.Marked)
+		}
+	})
+
+	s.T().Run("TestStatusGivenStatusWhenResolveStatusIsCalledThenMarkedShouldBeDerivedFromIt", func(t *testing.T) {
+		todo := &model.Todo{Status: model.StatusDone}
+
+		s.NoError(resolveStatus(stored(model.StatusBlocked), todo))
+		s.Equal(1, todo.Marked)
+	})
+
+	s.T().Run("TestStatusGivenMarkedArchivedTodoWhenResolveStatusIsCalledThenItShouldReturnErrInvalidTransition", func(t *testing.T) {
+		s.ErrorIs(resolveStatus(stored(model.StatusArchived), &model.Todo{Marked: 1}), ErrInvalidTransition)
+	})
+}
@@ -0,0 +1,528 @@
+// Package todotest provides a conformance suite every todo.DB implementation
+// must pass, so that new backends prove they behave exactly like Memory.
+package todotest
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+)
+
+// Factory returns an empty, ready to use DB. It is called once per test and
+// should register any teardown with t.Cleanup.
+type Factory func(t *testing.T) todo.DB
+
+// RunDBConformance runs the conformance suite against the DBs built by newDB.
+func RunDBConformance(t *testing.T, newDB Factory) {
+	suite.Run(t, &DBConformanceSuite{NewDB: newDB})
+}
+
+type DBConformanceSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	NewDB Factory
+	db    todo.DB
+	ctx   context.Context
+	owner int
+}
+
+func (s *DBConformanceSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.db = s.NewDB(s.T())
+	s.ctx = context.Background()
+	s.owner = 1
+}
+
+func (s *DBConformanceSuite) create(value string, status string) *model.Todo {
+	created, err := s.db.Create(s.ctx, &model.Todo{Value: value, Status: status, OwnerID: s.owner})
+	s.NoError(err)
+	return created
+}
+
+func (s *DBConformanceSuite) TestGetGivenExistingTodoIdThenItShouldReturnTheTodo() {
+	created := s.create("buy some milk", model.StatusDone)
+
+	actual, err := s.db.Get(s.ctx, s.owner, created.ID)
+
+	s.NoError(err)
+	s.Equal(&model.Todo{ID: created.ID, Value: "buy some milk", Status: model.StatusDone, Marked: 1, OwnerID: s.owner, Version: 1}, actual)
+}
+
+func (s *DBConformanceSuite) TestGetGivenUnExistingTodoIdThenItShouldReturnNilAndAnError() {
+	actual, err := s.db.Get(s.ctx, s.owner, 100)
+
+	s.ErrorIs(err, todo.ErrNotFound)
+	s.EqualError(err, "no todo found with id:100")
+	s.Nil(actual)
+}
+
+func (s *DBConformanceSuite) TestGetGivenNonPositiveTodoIdThenItShouldReturnNilAndAnError() {
+	for _, id := range []int{0, -1} {
+		actual, err := s.db.Get(s.ctx, s.owner, id)
+
+		s.ErrorIs(err, todo.ErrInvalidID)
+		s.Nil(actual)
+	}
+}
+
+func (s *DBConformanceSuite) TestListGivenEmptyStoreThenItShouldReturnAnEmptyNonNilList() {
+	actual, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{})
+
+	s.NoError(err)
+	s.NotNil(actual)
+	s.Empty(actual)
+}
+
+func (s *DBConformanceSuite) TestListGivenTodosThenItShouldReturnThemOrderedById() {
+	first := s.create("buy some milk", model.StatusOpen)
+	second := s.create("enjoy the assignment", model.StatusDone)
+	third := s.create("write some tests", model.StatusOpen)
+	s.NoError(s.db.Delete(s.ctx, s.owner, second.ID))
+	fourth := s.create("ship it", model.StatusOpen)
+
+	actual, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{})
+
+	s.NoError(err)
+	s.Equal([]*model.Todo{first, third, fourth}, actual)
+}
+
+func (s *DBConformanceSuite) TestCreateGivenClientIdThenItShouldBeIgnored() {
+	created, err := s.db.Create(s.ctx, &model.Todo{ID: 42, Value: "buy some milk", OwnerID: s.owner})
+	s.NoError(err)
+	s.NotEqual(42, created.ID)
+
+	_, err = s.db.Get(s.ctx, s.owner, 42)
+	s.Error(err)
+}
+
+func (s *DBConformanceSuite) TestCreateGivenTodosThenItShouldAssignIncreasingIdsThatAreNeverReused() {
+	first := s.create("buy some milk", model.StatusOpen)
+	second := s.create("enjoy the assignment", model.StatusOpen)
+	s.Greater(second.ID, first.ID)
+
+	s.NoError(s.db.Delete(s.ctx, s.owner, second.ID))
+
+	third := s.create("write some tests", model.StatusOpen)
+	s.Greater(third.ID, second.ID)
+}
+
+func (s *DBConformanceSuite) TestCreateGivenConcurrentCallsThenEveryTodoShouldGetAUniqueId() {
+	workers, perWorker := 8, 10
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if _, err := s.db.Create(s.ctx, &model.Todo{Value: "todo " + strconv.Itoa(w) + "-" + strconv.Itoa(i), OwnerID: s.owner}); err != nil {
+					s.T().Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	todos, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{})
+	s.NoError(err)
+	s.Len(todos, workers*perWorker)
+
+	seen := make(map[int]bool, len(todos))
+	for _, todo := range todos {
+		s.False(seen[todo.ID], "duplicate id %d", todo.ID)
+		seen[todo.ID] = true
+	}
+}
+
+func (s *DBConformanceSuite) TestCreateGivenReturnedTodoWhenItIsModifiedThenTheStoredTodoShouldNotChange() {
+	created := s.create("buy some milk", model.StatusOpen)
+	created.Value = "changed"
+
+	stored, err := s.db.Get(s.ctx, s.owner, created.ID)
+	s.NoError(err)
+	s.Equal("buy some milk", stored.Value)
+}
+
+func (s *DBConformanceSuite) TestMarkGivenExistingTodoThenItShouldReplaceValueAndStatus() {
+	created := s.create("buy some milk", model.StatusOpen)
+
+	actual, err := s.db.Mark(s.ctx, &model.Todo{ID: created.ID, Value: "buy some oat milk", Status: model.StatusDone, Marked: 1, OwnerID: s.owner}, created.Version)
+	s.NoError(err)
+	s.Equal(&model.Todo{ID: created.ID, Value: "buy some oat milk", Status: model.StatusDone, Marked: 1, OwnerID: s.owner, Version: created.Version + 1}, actual)
+
+	stored, err := s.db.Get(s.ctx, s.owner, created.ID)
+	s.NoError(err)
+	s.Equal(actual, stored)
+}
+
+func (s *DBConformanceSuite) TestMarkGivenUnExistingTodoThenItShouldReturnNilAndAnError() {
+	actual, err := s.db.Mark(s.ctx, &model.Todo{ID: 100, Status: model.StatusDone, Marked: 1, OwnerID: s.owner}, 1)
+
+	s.ErrorIs(err, todo.ErrNotFound)
+	s.EqualError(err, "no todo found with id:100")
+	s.Nil(actual)
+}
+
+func (s *DBConformanceSuite) TestMarkGivenTodoChangedSinceItWasReadThenItShouldReturnErrConflict() {
+	created := s.create("buy some milk", model.StatusOpen)
+	s.Equal(1, created.Version)
+
+	done, err := s.db.Mark(s.ctx, &model.Todo{ID: created.ID, Value: "buy some milk", Status: model.StatusDone, Marked: 1, OwnerID: s.owner}, created.Version)
+	s.NoError(err)
+	s.Equal(2, done.Version)
+
+	actual, err := s.db.Mark(s.ctx, &model.Todo{ID: created.ID, Value: "buy some oat milk", Status: model.StatusOpen, OwnerID: s.owner}, created.Version)
+	s.ErrorIs(err, todo.ErrConflict)
+	s.EqualError(err, "todo conflict with id:"+strconv.Itoa(created.ID)+", it was changed by another request")
+	s.Nil(actual)
+
+	stored, err := s.db.Get(s.ctx, s.owner, created.ID)
+	s.NoError(err)
+	s.Equal(done, stored)
+}
+
+func (s *DBConformanceSuite) TestDeleteGivenExistingIdThenTheTodoShouldBeGone() {
+	created := s.create("buy some milk", model.StatusOpen)
+
+	s.NoError(s.db.Delete(s.ctx, s.owner, created.ID))
+
+	_, err := s.db.Get(s.ctx, s.owner, created.ID)
+	s.EqualError(err, "no todo found with id:"+strconv.Itoa(created.ID))
+}
+
+func (s *DBConformanceSuite) TestDeleteGivenUnExistingIdThenItShouldReturnAnError() {
+	err := s.db.Delete(s.ctx, s.owner, 100)
+
+	s.ErrorIs(err, todo.ErrNotFound)
+	s.EqualError(err, "no todo found with id:100")
+}
+
+func (s *DBConformanceSuite) TestDeleteGivenAlreadyDeletedIdThenItShouldReturnAnError() {
+	created := s.create("buy some milk", model.StatusOpen)
+	s.NoError(s.db.Delete(s.ctx, s.owner, created.ID))
+
+	s.EqualError(s.db.Delete(s.ctx, s.owner, created.ID), "no todo found with id:"+strconv.Itoa(created.ID))
+}
+
+func (s *DBConformanceSuite) TestListGivenMarkedFilterThenItShouldReturnOnlyMatchingTodos() {
+	s.create("buy some milk", model.StatusOpen)
+	marked := s.create("enjoy the assignment", model.StatusDone)
+	one := 1
+
+	actual, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{Marked: &one})
+
+	s.NoError(err)
+	s.Equal([]*model.Todo{marked}, actual)
+}
+
+func (s *DBConformanceSuite) TestListGivenStatusFilterThenItShouldReturnOnlyMatchingTodos() {
+	s.create("buy some milk", model.StatusOpen)
+	blocked := s.create("enjoy the assignment", model.StatusBlocked)
+	s.create("write some tests", model.StatusDone)
+	alsoBlocked := s.create("ship it", model.StatusBlocked)
+
+	actual, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{Status: model.StatusBlocked})
+
+	s.NoError(err)
+	s.Equal([]*model.Todo{blocked, alsoBlocked}, actual)
+}
+
+func (s *DBConformanceSuite) TestEveryWriteGivenStatusThenMarkedShouldBeDerivedFromIt() {
+	for _, status := range []string{model.StatusOpen, model.StatusInProgress, model.StatusBlocked, model.StatusDone, model.StatusArchived} {
+		created, err := s.db.Create(s.ctx, &model.Todo{Value: status, Status: status, Marked: 1 - model.MarkedFor(status), OwnerID: s.owner})
+		s.NoError(err)
+		s.Equal(model.MarkedFor(status), created.Marked, status)
+
+		created.Marked = 1 - created.Marked
+		marked, err := s.db.Mark(s.ctx, created, created.Version)
+		s.NoError(err)
+		s.Equal(model.MarkedFor(status), marked.Marked, status)
+	}
+}
+
+func (s *DBConformanceSuite) TestCreateGivenEveryFieldThenItShouldBeStoredAndReturned() {
+	due := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	completed := created.Add(time.Hour)
+	expected := &model.Todo{
+		Value:       "buy some milk",
+		Description: "the oat one",
+		DueDate:     &due,
+		Priority:    model.PriorityHigh,
+		Status:      model.StatusDone,
+		Marked:      1,
+		CreatedAt:   created,
+		UpdatedAt:   completed,
+		CompletedAt: &completed,
+		OwnerID:     s.owner,
+	}
+
+	actual, err := s.db.Create(s.ctx, expected)
+	s.NoError(err)
+	expected.ID, expected.Version = actual.ID, 1
+	s.Equal(expected, actual)
+
+	stored, err := s.db.Get(s.ctx, s.owner, actual.ID)
+	s.NoError(err)
+	s.Equal(expected, stored)
+
+	expected.Description, expected.DueDate, expected.Priority, expected.CompletedAt = "", nil, "", nil
+	expected.Status, expected.Marked = model.StatusOpen, 0
+	actual, err = s.db.Mark(s.ctx, expected, 1)
+	s.NoError(err)
+	expected.Version = 2
+	s.Equal(expected, actual)
+}
+
+func (s *DBConformanceSuite) TestListGivenPriorityAndDueDateFiltersThenItShouldReturnOnlyMatchingTodos() {
+	at := func(day int) *time.Time {
+		t := time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC)
+		return &t
+	}
+	create := func(value string, priority string, due *time.Time) *model.Todo {
+		created, err := s.db.Create(s.ctx, &model.Todo{Value: value, Priority: priority, DueDate: due, OwnerID: s.owner})
+		s.NoError(err)
+		return created
+	}
+	early := create("buy some milk", model.PriorityHigh, at(1))
+	late := create("enjoy the assignment", model.PriorityHigh, at(3))
+	low := create("write some tests", model.PriorityLow, at(2))
+	undated := create("ship it", model.PriorityHigh, nil)
+
+	cases := []struct {
+		name     string
+		opts     model.ListOptions
+		expected []*model.Todo
+	}{
+		{"priority", model.ListOptions{Priority: model.PriorityHigh}, []*model.Todo{early, late, undated}},
+		{"due before", model.ListOptions{DueBefore: at(3)}, []*model.Todo{early, low}},
+		{"due after", model.ListOptions{DueAfter: at(1)}, []*model.Todo{late, low}},
+		{"due between", model.ListOptions{DueAfter: at(1), DueBefore: at(3)}, []*model.Todo{low}},
+		{"every filter", model.ListOptions{Priority: model.PriorityHigh, DueBefore: at(2)}, []*model.Todo{early}},
+	}
+
+	for _, c := range cases {
+		actual, _, err := s.db.List(s.ctx, s.owner, c.opts)
+
+		s.NoError(err)
+		s.ElementsMatch(c.expected, actual, c.name)
+	}
+
+	offset := time.Date(2024, 1, 2, 15, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	actual, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{DueBefore: &offset})
+	s.NoError(err)
+	s.Equal([]*model.Todo{early}, actual)
+}
+
+func (s *DBConformanceSuite) TestListGivenContainsFilterThenItShouldMatchCaseInsensitively() {
+	milk := s.create("Buy some MILK", model.StatusOpen)
+	s.create("enjoy the assignment", model.StatusOpen)
+	percent := s.create("grow 100% more milk", model.StatusDone)
+
+	actual, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{Contains: "milk"})
+	s.NoError(err)
+	s.Equal([]*model.Todo{milk, percent}, actual)
+
+	actual, _, err = s.db.List(s.ctx, s.owner, model.ListOptions{Contains: "0%"})
+	s.NoError(err)
+	s.Equal([]*model.Todo{percent}, actual)
+
+	actual, _, err = s.db.List(s.ctx, s.owner, model.ListOptions{Contains: "_"})
+	s.NoError(err)
+	s.Empty(actual)
+}
+
+func (s *DBConformanceSuite) TestListGivenNonASCIIContainsFilterThenOnlyASCIILettersShouldMatchCaseInsensitively() {
+	upper := s.create("ÇAY demle", model.StatusOpen)
+	lower := s.create("çay iç", model.StatusOpen)
+
+	actual, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{Contains: "ÇAY"})
+	s.NoError(err)
+	s.Equal([]*model.Todo{upper}, actual)
+
+	actual, _, err = s.db.List(s.ctx, s.owner, model.ListOptions{Contains: "çAy"})
+	s.NoError(err)
+	s.Equal([]*model.Todo{lower}, actual)
+
+	actual, _, err = s.db.List(s.ctx, s.owner, model.ListOptions{Contains: "AY"})
+	s.NoError(err)
+	s.Equal([]*model.Todo{upper, lower}, actual)
+}
+
+func (s *DBConformanceSuite) TestListGivenSortAndOrderThenItShouldOrderByTheFieldThenById() {
+	b1 := s.create("b", model.StatusDone)
+	a := s.create("a", model.StatusOpen)
+	b2 := s.create("b", model.StatusOpen)
+	upper := s.create("B", model.StatusDone)
+
+	cases := []struct {
+		sort, order string
+		expected    []*model.Todo
+	}{
+		{model.SortByID, model.OrderDesc, []*model.Todo{upper, b2, a, b1}},
+		{model.SortByValue, model.OrderAsc, []*model.Todo{upper, a, b1, b2}},
+		{model.SortByValue, model.OrderDesc, []*model.Todo{b2, b1, a, upper}},
+		{model.SortByMarked, model.OrderAsc, []*model.Todo{a, b2, b1, upper}},
+		{model.SortByMarked, model.OrderDesc, []*model.Todo{upper, b1, b2, a}},
+	}
+
+	for _, c := range cases {
+		actual, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{Sort: c.sort, Order: c.order})
+
+		s.NoError(err)
+		s.Equal(c.expected, actual, c.sort+" "+c.order)
+	}
+}
+
+func (s *DBConformanceSuite) TestListGivenLimitThenFollowingCursorsShouldVisitEveryTodoOnce() {
+	for i, value := range []string{"d", "a", "c", "a", "b", "d", "c"} {
+		s.create(value, []string{model.StatusOpen, model.StatusDone}[i%2])
+	}
+
+	for _, sort := range []string{model.SortByID, model.SortByValue, model.SortByMarked} {
+		for _, order := range []string{model.OrderAsc, model.OrderDesc} {
+			expected, page, err := s.db.List(s.ctx, s.owner, model.ListOptions{Sort: sort, Order: order})
+			s.NoError(err)
+			s.False(page.HasMore)
+
+			opts := model.ListOptions{Limit: 2, Sort: sort, Order: order}
+			visited := []*model.Todo{}
+			for pages := 1; ; pages++ {
+				todos, page, err := s.db.List(s.ctx, s.owner, opts)
+				s.NoError(err)
+				s.LessOrEqual(len(todos), 2)
+				s.Equal(2, page.Limit)
+				visited = append(visited, todos...)
+
+				if !page.HasMore {
+					s.Empty(page.NextCursor)
+					s.Equal(4, pages, sort+" "+order)
+					break
+				}
+				s.NotEmpty(page.NextCursor)
+				opts.Cursor = page.NextCursor
+			}
+
+			s.Equal(expected, visited, sort+" "+order)
+		}
+	}
+}
+
+func (s *DBConformanceSuite) TestListGivenLimitMatchingTheTotalThenThereShouldBeNoNextPage() {
+	s.create("buy some milk", model.StatusOpen)
+	s.create("enjoy the assignment", model.StatusOpen)
+
+	actual, page, err := s.db.List(s.ctx, s.owner, model.ListOptions{Limit: 2})
+
+	s.NoError(err)
+	s.Len(actual, 2)
+	s.False(page.HasMore)
+	s.Empty(page.NextCursor)
+}
+
+func (s *DBConformanceSuite) TestListGivenInvalidOptionsThenItShouldReturnAValidationError() {
+	s.create("buy some milk", model.StatusOpen)
+	s.create("enjoy the assignment", model.StatusOpen)
+	_, page, err := s.db.List(s.ctx, s.owner, model.ListOptions{Limit: 1})
+	s.NoError(err)
+
+	two := 2
+	for _, opts := range []model.ListOptions{
+		{Sort: "priority"},
+		{Priority: "urgent"},
+		{Status: "finished"},
+		{Order: "sideways"},
+		{Limit: -1},
+		{Marked: &two},
+		{Cursor: "not a cursor"},
+		{Limit: 1, Cursor: page.NextCursor, Sort: model.SortByValue},
+		{Limit: 1, Cursor: page.NextCursor, Order: model.OrderDesc},
+	} {
+		_, _, err := s.db.List(s.ctx, s.owner, opts)
+
+		s.ErrorIs(err, apperr.ErrValidation)
+	}
+}
+
+func (s *DBConformanceSuite) TestCountGivenTodosOfSeveralOwnersThenItShouldCountAllOfThem() {
+	count, err := s.db.Count(s.ctx)
+	s.NoError(err)
+	s.Equal(0, count)
+
+	s.create("buy some milk", model.StatusOpen)
+	deleted := s.create("enjoy the assignment", model.StatusDone)
+	_, err = s.db.Create(s.ctx, &model.Todo{Value: "someone else's", OwnerID: s.owner + 1})
+	s.NoError(err)
+	s.NoError(s.db.Delete(s.ctx, s.owner, deleted.ID))
+
+	count, err = s.db.Count(s.ctx)
+	s.NoError(err)
+	s.Equal(2, count)
+}
+
+func (s *DBConformanceSuite) TestPingGivenReachableBackendThenItShouldSucceed() {
+	s.NoError(s.db.Ping(s.ctx))
+}
+
+func (s *DBConformanceSuite) TestEveryMethodGivenCanceledContextThenItShouldReturnTheContextError() {
+	created := s.create("buy some milk", model.StatusOpen)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.db.Get(ctx, s.owner, created.ID)
+	s.ErrorIs(err, context.Canceled)
+
+	_, _, err = s.db.List(ctx, s.owner, model.ListOptions{})
+	s.ErrorIs(err, context.Canceled)
+
+	_, err = s.db.Create(ctx, &model.Todo{Value: "enjoy the assignment", OwnerID: s.owner})
+	s.ErrorIs(err, context.Canceled)
+
+	_, err = s.db.Mark(ctx, &model.Todo{ID: created.ID, Value: "buy some milk", Status: model.StatusDone, Marked: 1, OwnerID: s.owner}, created.Version)
+	s.ErrorIs(err, context.Canceled)
+
+	s.ErrorIs(s.db.Delete(ctx, s.owner, created.ID), context.Canceled)
+
+	_, err = s.db.Count(ctx)
+	s.ErrorIs(err, context.Canceled)
+
+	s.ErrorIs(s.db.Ping(ctx), context.Canceled)
+
+	stored, err := s.db.Get(s.ctx, s.owner, created.ID)
+	s.NoError(err)
+	s.Equal(created, stored)
+}
+
+func (s *DBConformanceSuite) TestEveryMethodGivenTodoOfAnotherOwnerThenItShouldBehaveAsIfItDidNotExist() {
+	mine := s.create("buy some milk", model.StatusOpen)
+	other := 2
+	theirs, err := s.db.Create(s.ctx, &model.Todo{Value: "enjoy the assignment", OwnerID: other})
+	s.NoError(err)
+
+	_, err = s.db.Get(s.ctx, s.owner, theirs.ID)
+	s.ErrorIs(err, todo.ErrNotFound)
+
+	todos, _, err := s.db.List(s.ctx, s.owner, model.ListOptions{})
+	s.NoError(err)
+	s.Equal([]*model.Todo{mine}, todos)
+
+	_, err = s.db.Mark(s.ctx, &model.Todo{ID: theirs.ID, Value: "hijacked", Status: model.StatusDone, Marked: 1, OwnerID: s.owner}, theirs.Version)
+	s.ErrorIs(err, todo.ErrNotFound)
+
+	s.ErrorIs(s.db.Delete(s.ctx, s.owner, theirs.ID), todo.ErrNotFound)
+
+	stored, err := s.db.Get(s.ctx, other, theirs.ID)
+	s.NoError(err)
+	s.Equal(theirs, stored)
+}
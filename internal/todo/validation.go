@@ -0,0 +1,72 @@
+package todo
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+const (
+	// MaxValueLength is the maximum number of characters of a todo value.
+	MaxValueLength = 500
+	// MaxDescriptionLength is the maximum number of characters of a todo
+	// description.
+	MaxDescriptionLength = 5000
+)
+
+// validateTodo normalizes todo in place, trimming its texts and moving the due
+// date to UTC to the second, and reports every field that breaks the payload
+// rules.
+func validateTodo(todo *model.Todo) error {
+	errs := apperr.ValidationErrors{}
+
+	todo.Value = strings.TrimSpace(todo.Value)
+	if todo.Value == "" {
+		errs = append(errs, &apperr.ValidationError{Field: "value", Message: "must not be empty"})
+	} else if utf8.RuneCountInString(todo.Value) > MaxValueLength {
+		errs = append(errs, &apperr.ValidationError{Field: "value", Message: "must be at most " + strconv.Itoa(MaxValueLength) + " characters"})
+	}
+
+	todo.Description = strings.TrimSpace(todo.Description)
+	if utf8.RuneCountInString(todo.Description) > MaxDescriptionLength {
+		errs = append(errs, &apperr.ValidationError{Field: "description", Message: "must be at most " + strconv.Itoa(MaxDescriptionLength) + " characters"})
+	}
+
+	if todo.DueDate != nil {
+		due := todo.DueDate.UTC().Truncate(time.Second)
+		todo.DueDate = &due
+	}
+
+	if !validPriority(todo.Priority) {
+		errs = append(errs, &apperr.ValidationError{Field: "priority", Message: "must be one of low, medium, high"})
+	}
+
+	if todo.Status != "" && !validStatus(todo.Status) {
+		errs = append(errs, &apperr.ValidationError{Field: "status", Message: statusMessage})
+	}
+
+	if todo.Marked != 0 && todo.Marked != 1 {
+		errs = append(errs, &apperr.ValidationError{Field: "marked", Message: "must be 0 or 1"})
+	} else if todo.Marked == 1 && todo.Status != "" && todo.Status != model.StatusDone {
+		errs = append(errs, &apperr.ValidationError{Field: "marked", Message: "must be 0 unless status is done"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validPriority accepts the priority levels and the empty priority.
+func validPriority(priority string) bool {
+	switch priority {
+	case "", model.PriorityLow, model.PriorityMedium, model.PriorityHigh:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+type ValidationSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestValidationSuite(t *testing.T) {
+	suite.Run(t, new(ValidationSuite))
+}
+
+func (s *ValidationSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+}
+
+func (s *ValidationSuite) TestValidationGivenWhenValidateTodoIsCalled() {
+	s.T().Run("TestValidationGivenValueWithSurroundingSpacesWhenValidateTodoIsCalledThenItShouldTrimTheValue", func(t *testing.T) {
+		todo := &model.Todo{Value: "  buy some milk \n", Marked: 1}
+
+		s.NoError(validateTodo(todo))
+		s.Equal("buy some milk", todo.Value)
+	})
+
+	s.T().Run("TestValidationGivenValueOfMaxLengthWhenValidateTodoIsCalledThenItShouldBeAccepted", func(t *testing.T) {
+		s.NoError(validateTodo(&model.Todo{Value: strings.Repeat("ü", MaxValueLength)}))
+	})
+
+	s.T().Run("TestValidationGivenEveryFieldInvalidWhenValidateTodoIsCalledThenItShouldReportEachField", func(t *testing.T) {
+		err := validateTodo(&model.Todo{Value: "   ", Description: strings.Repeat("a", MaxDescriptionLength+1), Priority: "urgent", Status: "finished", Marked: 42})
+
+		s.ErrorIs(err, apperr.ErrValidation)
+		s.Equal(apperr.ValidationErrors{
+			{Field: "value", Message: "must not be empty"},
+			{Field: "description", Message: "must be at most 5000 characters"},
+			{Field: "priority", Message: "must be one of low, medium, high"},
+			{Field: "status", Message: "must be one of open, in_progress, blocked, done, archived"},
+			{Field: "marked", Message: "must be 0 or 1"},
+		}, err)
+	})
+
+	s.T().Run("TestValidationGivenMarkedTodoWithAnotherStatusWhenValidateTodoIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		err := validateTodo(&model.Todo{Value: "buy some milk", Status: model.StatusBlocked, Marked: 1})
+
+		s.EqualError(err, "marked: must be 0 unless status is done")
+	})
+
+	s.T().Run("TestValidationGivenDueDateWithOffsetWhenValidateTodoIsCalledThenItShouldBeMovedToUTCToTheSecond", func(t *testing.T) {
+		due := time.Date(2024, 1, 2, 9, 30, 15, 999, time.FixedZone("UTC-5", -5*60*60))
+		todo := &model.Todo{Value: "buy some milk", Description: " the oat one ", DueDate: &due, Priority: model.PriorityLow}
+
+		s.NoError(validateTodo(todo))
+		s.Equal(time.Date(2024, 1, 2, 14, 30, 15, 0, time.UTC), *todo.DueDate)
+		s.Equal("the oat one", todo.Description)
+	})
+
+	s.T().Run("TestValidationGivenTooLongValueWhenValidateTodoIsCalledThenItShouldReturnAValidationError", func(t *testing.T) {
+		err := validateTodo(&model.Todo{Value: strings.Repeat("a", MaxValueLength+1)})
+
+		s.EqualError(err, "value: must be at most 500 characters")
+	})
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apikey"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// tracedAPIKeyDB records a span for every call made to the wrapped apikey.DB.
+type tracedAPIKeyDB struct {
+	db     apikey.DB
+	tracer trace.Tracer
+}
+
+// TraceAPIKeyDB returns db with each of its methods recorded as an
+// "apikey.DB/<method>" span.
+func TraceAPIKeyDB(tracer trace.Tracer, db apikey.DB) apikey.DB {
+	return &tracedAPIKeyDB{db: db, tracer: tracer}
+}
+
+func (t *tracedAPIKeyDB) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "apikey.DB/"+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func (t *tracedAPIKeyDB) Create(ctx context.Context, k *model.APIKey) (created *model.APIKey, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer func() { endWith(span, err, apikey.StatusCode) }()
+	return t.db.Create(ctx, k)
+}
+
+func (t *tracedAPIKeyDB) List(ctx context.Context, ownerID int) (keys []*model.APIKey, err error) {
+	ctx, span := t.start(ctx, "List")
+	defer func() {
+		span.SetAttributes(attribute.Int("apikey.list.count", len(keys)))
+		endWith(span, err, apikey.StatusCode)
+	}()
+	return t.db.List(ctx, ownerID)
+}
+
+func (t *tracedAPIKeyDB) GetByPrefix(ctx context.Context, prefix string) (k *model.APIKey, err error) {
+	ctx, span := t.start(ctx, "GetByPrefix")
+	defer func() { endWith(span, err, apikey.StatusCode) }()
+	return t.db.GetByPrefix(ctx, prefix)
+}
+
+func (t *tracedAPIKeyDB) Revoke(ctx context.Context, ownerID int, id int, at time.Time) (revoked *model.APIKey, err error) {
+	ctx, span := t.start(ctx, "Revoke", attribute.Int("apikey.id", id))
+	defer func() { endWith(span, err, apikey.StatusCode) }()
+	return t.db.Revoke(ctx, ownerID, id, at)
+}
+
+// tracedAPIKeyService records a span for every call made to the wrapped
+// apikey.Service, except Log. Keys are left out of the spans.
+type tracedAPIKeyService struct {
+	service apikey.Service
+	tracer  trace.Tracer
+}
+
+// TraceAPIKeyService returns service with each of its methods recorded as an
+// "APIKeyService/<method>" span.
+func TraceAPIKeyService(tracer trace.Tracer, service apikey.Service) apikey.Service {
+	return &tracedAPIKeyService{service: service, tracer: tracer}
+}
+
+func (t *tracedAPIKeyService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "APIKeyService/"+method, trace.WithAttributes(attrs...))
+}
+
+func (t *tracedAPIKeyService) Create(ctx context.Context, k model.NewAPIKey) (created *model.CreatedAPIKey, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer func() { endWith(span, err, apikey.StatusCode) }()
+	return t.service.Create(ctx, k)
+}
+
+func (t *tracedAPIKeyService) List(ctx context.Context) (keys []*model.APIKey, err error) {
+	ctx, span := t.start(ctx, "List")
+	defer func() { endWith(span, err, apikey.StatusCode) }()
+	return t.service.List(ctx)
+}
+
+func (t *tracedAPIKeyService) Revoke(ctx context.Context, id int) (revoked *model.APIKey, err error) {
+	ctx, span := t.start(ctx, "Revoke", attribute.Int("apikey.id", id))
+	defer func() { endWith(span, err, apikey.StatusCode) }()
+	return t.service.Revoke(ctx, id)
+}
+
+func (t *tracedAPIKeyService) Verify(ctx context.Context, key string) (identity auth.Identity, err error) {
+	ctx, span := t.start(ctx, "Verify")
+	defer func() {
+		if identity.APIKeyID != 0 {
+			span.SetAttributes(attribute.Int("apikey.id", identity.APIKeyID))
+		}
+		endWith(span, err, verifyStatusCode)
+	}()
+	return t.service.Verify(ctx, key)
+}
+
+// verifyStatusCode maps the errors of Verify, which answers
+// auth.ErrInvalidToken for keys that are rejected.
+func verifyStatusCode(err error) int {
+	if errors.Is(err, auth.ErrInvalidToken) {
+		return http.StatusUnauthorized
+	}
+
+	return apikey.StatusCode(err)
+}
+
+func (t *tracedAPIKeyService) Log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	t.service.Log(ctx, level, msg, args...)
+}
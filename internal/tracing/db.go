@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+)
+
+// tracedDB records a span for every call made to the wrapped todo.DB.
+type tracedDB struct {
+	db     todo.DB
+	tracer trace.Tracer
+}
+
+// TraceDB returns db with each of its methods recorded as a "todo.DB/<method>"
+// span.
+func TraceDB(tracer trace.Tracer, db todo.DB) todo.DB {
+	return &tracedDB{db: db, tracer: tracer}
+}
+
+func (t *tracedDB) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "todo.DB/"+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func (t *tracedDB) Get(ctx context.Context, ownerID int, id int) (todo *model.Todo, err error) {
+	ctx, span := t.start(ctx, "Get", attribute.Int("todo.id", id))
+	defer func() { end(span, err) }()
+	return t.db.Get(ctx, ownerID, id)
+}
+
+func (t *tracedDB) List(ctx context.Context, ownerID int, opts model.ListOptions) (todos []*model.Todo, page model.Pagination, err error) {
+	ctx, span := t.start(ctx, "List", attribute.Int("todo.list.limit", opts.Limit), attribute.String("todo.list.sort", opts.Sort))
+	defer func() {
+		span.SetAttributes(attribute.Int("todo.list.count", len(todos)))
+		end(span, err)
+	}()
+	return t.db.List(ctx, ownerID, opts)
+}
+
+func (t *tracedDB) Create(ctx context.Context, todo *model.Todo) (created *model.Todo, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer func() { end(span, err) }()
+	return t.db.Create(ctx, todo)
+}
+
+func (t *tracedDB) Mark(ctx context.Context, todo *model.Todo, version int) (marked *model.Todo, err error) {
+	ctx, span := t.start(ctx, "Mark", attribute.Int("todo.id", todo.ID))
+	defer func() { end(span, err) }()
+	return t.db.Mark(ctx, todo, version)
+}
+
+func (t *tracedDB) Delete(ctx context.Context, ownerID int, id int) (err error) {
+	ctx, span := t.start(ctx, "Delete", attribute.Int("todo.id", id))
+	defer func() { end(span, err) }()
+	return t.db.Delete(ctx, ownerID, id)
+}
+
+func (t *tracedDB) Count(ctx context.Context) (count int, err error) {
+	ctx, span := t.start(ctx, "Count")
+	defer func() { end(span, err) }()
+	return t.db.Count(ctx)
+}
+
+func (t *tracedDB) Ping(ctx context.Context) (err error) {
+	ctx, span := t.start(ctx, "Ping")
+	defer func() { end(span, err) }()
+	return t.db.Ping(ctx)
+}
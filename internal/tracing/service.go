@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+)
+
+// tracedService records a span for every call made to the wrapped
+// todo.Service, except Log.
+type tracedService struct {
+	service todo.Service
+	tracer  trace.Tracer
+}
+
+// TraceService returns service with each of its methods recorded as a
+// "TodoService/<method>" span.
+func TraceService(tracer trace.Tracer, service todo.Service) todo.Service {
+	return &tracedService{service: service, tracer: tracer}
+}
+
+func (t *tracedService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "TodoService/"+method, trace.WithAttributes(attrs...))
+}
+
+func (t *tracedService) Get(ctx context.Context, id int) (todo *model.Todo, err error) {
+	ctx, span := t.start(ctx, "Get", attribute.Int("todo.id", id))
+	defer func() { end(span, err) }()
+	return t.service.Get(ctx, id)
+}
+
+func (t *tracedService) List(ctx context.Context, opts model.ListOptions) (todos []*model.Todo, page model.Pagination, err error) {
+	ctx, span := t.start(ctx, "List")
+	defer func() { end(span, err) }()
+	return t.service.List(ctx, opts)
+}
+
+func (t *tracedService) Create(ctx context.Context, todo *model.Todo) (created *model.Todo, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer func() { end(span, err) }()
+	return t.service.Create(ctx, todo)
+}
+
+func (t *tracedService) Mark(ctx context.Context, todo *model.Todo) (marked *model.Todo, err error) {
+	ctx, span := t.start(ctx, "Mark", attribute.Int("todo.id", todo.ID))
+	defer func() { end(span, err) }()
+	return t.service.Mark(ctx, todo)
+}
+
+func (t *tracedService) Patch(ctx context.Context, id int, patch []byte) (patched *model.Todo, err error) {
+	ctx, span := t.start(ctx, "Patch", attribute.Int("todo.id", id))
+	defer func() { end(span, err) }()
+	return t.service.Patch(ctx, id, patch)
+}
+
+func (t *tracedService) Transition(ctx context.Context, id int, status string) (moved *model.Todo, err error) {
+	ctx, span := t.start(ctx, "Transition", attribute.Int("todo.id", id), attribute.String("todo.status", status))
+	defer func() { end(span, err) }()
+	return t.service.Transition(ctx, id, status)
+}
+
+func (t *tracedService) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := t.start(ctx, "Delete", attribute.Int("todo.id", id))
+	defer func() { end(span, err) }()
+	return t.service.Delete(ctx, id)
+}
+
+func (t *tracedService) Log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	t.service.Log(ctx, level, msg, args...)
+}
@@ -0,0 +1,107 @@
+// Package tracing sets up OpenTelemetry tracing and wraps the todo service
+// and store so every call to them is recorded as a span.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+)
+
+// Name identifies the spans of the application among those of its libraries.
+const Name = "github.com/tarkanaciksoz/api-todo-app"
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Propagator reads and writes the W3C traceparent and baggage headers.
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// Setup installs the global tracer provider selected by config.Tracing and
+// returns the function flushing and stopping it. Spans of the stdout exporter
+// are written to w. Without an exporter the global no-op provider is kept.
+func Setup(ctx context.Context, config model.Config, w io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(Propagator)
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Tracing.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if config.Tracing.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Tracing.OTLPEndpoint))
+		}
+		if config.Tracing.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown TRACING_EXPORTER %q", config.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("api-todo-app"),
+		semconv.DeploymentEnvironment(config.AppEnv),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of the application from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(Name)
+}
+
+// end records err on span, marking the span as failed only for errors that
+// are the server's fault, and ends it.
+func end(span trace.Span, err error) {
+	endWith(span, err, todo.StatusCode)
+}
+
+// endWith is end for errors of a package mapped to HTTP statuses by
+// statusCode.
+func endWith(span trace.Span, err error, statusCode func(error) int) {
+	if err != nil {
+		span.RecordError(err)
+		if statusCode(err) >= 500 {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
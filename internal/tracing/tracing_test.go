@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apikey"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+	"github.com/tarkanaciksoz/api-todo-app/internal/user"
+)
+
+type TracingSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	recorder *tracetest.SpanRecorder
+	tracer   trace.Tracer
+	service  todo.Service
+	ctx      context.Context
+}
+
+func TestTracingSuite(t *testing.T) {
+	suite.Run(t, new(TracingSuite))
+}
+
+func (s *TracingSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.recorder = tracetest.NewSpanRecorder()
+	s.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)).Tracer(Name)
+
+	db := TraceDB(s.tracer, todo.NewDB())
+	s.service = TraceService(s.tracer, todo.NewTodoService(logging.Discard(), db))
+	s.ctx = auth.WithIdentity(context.Background(), auth.Identity{UserID: 1})
+}
+
+func (s *TracingSuite) TestTracingGivenWhenTheServiceIsCalled() {
+	s.T().Run("TestTracingGivenParentSpanWhenAServiceMethodIsCalledThenTheServiceAndStoreSpansShouldBeNestedUnderIt", func(t *testing.T) {
+		ctx, parent := s.tracer.Start(s.ctx, "parent")
+		_, err := s.service.Create(ctx, &model.Todo{Value: "buy some milk"})
+		s.NoError(err)
+		parent.End()
+
+		spans := s.recorder.Ended()
+		s.Len(spans, 3)
+		s.Equal("todo.DB/Create", spans[0].Name())
+		s.Equal("TodoService/Create", spans[1].Name())
+		s.Equal(spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+		s.Equal(parent.SpanContext().SpanID(), spans[1].Parent().SpanID())
+		s.Equal(parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	})
+
+	s.T().Run("TestTracingGivenUnExistingTodoWhenItIsRequestedThenTheErrorShouldBeRecordedWithoutFailingTheSpan", func(t *testing.T) {
+		_, err := s.service.Get(s.ctx, 999)
+		s.ErrorIs(err, todo.ErrNotFound)
+
+		spans := s.recorder.Ended()
+		span := spans[len(spans)-1]
+		s.Equal("TodoService/Get", span.Name())
+		s.Equal(codes.Unset, span.Status().Code)
+		s.Len(span.Events(), 1)
+		s.Equal("exception", span.Events()[0].Name)
+	})
+
+	s.T().Run("TestTracingGivenCanceledContextWhenTheStoreIsCalledThenTheSpanShouldBeMarkedAsFailed", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(s.ctx, 0)
+		defer cancel()
+
+		_, _, err := s.service.List(ctx, model.ListOptions{})
+		s.ErrorIs(err, context.DeadlineExceeded)
+
+		spans := s.recorder.Ended()
+		span := spans[len(spans)-2]
+		s.Equal("todo.DB/List", span.Name())
+		s.Equal(codes.Error, span.Status().Code)
+	})
+}
+
+func (s *TracingSuite) TestTracingGivenWhenTheUserAndAPIKeyServicesAreCalled() {
+	tokens, err := auth.NewJWT(model.Config{TokenTTL: time.Hour, JWTSecret: "tracing-test-secret-of-at-least-32-bytes"})
+	s.NoError(err)
+	users := TraceUserService(s.tracer, user.NewUserService(logging.Discard(), TraceUserDB(s.tracer, user.NewDB()), tokens))
+	keys := TraceAPIKeyService(s.tracer, apikey.NewAPIKeyService(logging.Discard(), TraceAPIKeyDB(s.tracer, apikey.NewDB())))
+
+	s.T().Run("TestTracingGivenRegistrationWhenItSucceedsThenTheServiceAndStoreSpansShouldBeNested", func(t *testing.T) {
+		_, err := users.Register(context.Background(), model.Credentials{Username: "alice", Password: "correct horse"})
+		s.NoError(err)
+
+		spans := s.recorder.Ended()
+		service := spans[len(spans)-1]
+		s.Equal("UserService/Register", service.Name())
+		s.Equal("user.DB/Create", spans[len(spans)-2].Name())
+		s.Equal(service.SpanContext().SpanID(), spans[len(spans)-2].Parent().SpanID())
+	})
+
+	s.T().Run("TestTracingGivenWrongPasswordWhenLoginIsCalledThenTheErrorShouldBeRecordedWithoutFailingTheSpan", func(t *testing.T) {
+		_, err := users.Login(context.Background(), model.Credentials{Username: "alice", Password: "wrong password"})
+		s.ErrorIs(err, user.ErrInvalidCredentials)
+
+		spans := s.recorder.Ended()
+		span := spans[len(spans)-1]
+		s.Equal("UserService/Login", span.Name())
+		s.Equal(codes.Unset, span.Status().Code)
+		s.Len(span.Events(), 1)
+	})
+
+	s.T().Run("TestTracingGivenBogusAPIKeyWhenItIsVerifiedThenTheLookupShouldBeTraced", func(t *testing.T) {
+		_, err := keys.Verify(context.Background(), "tda_0123456789abcdef_bogus")
+		s.ErrorIs(err, auth.ErrInvalidToken)
+
+		spans := s.recorder.Ended()
+		s.Equal("apikey.DB/GetByPrefix", spans[len(spans)-2].Name())
+		s.Equal("APIKeyService/Verify", spans[len(spans)-1].Name())
+		s.Equal(codes.Unset, spans[len(spans)-1].Status().Code)
+	})
+}
+
+func (s *TracingSuite) TestTracingGivenWhenItIsSetUp() {
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	s.T().Run("TestTracingGivenStdoutExporterWhenSpansAreEndedThenTheyShouldBeWrittenOnShutdown", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		shutdown, err := Setup(context.Background(), model.Config{Tracing: model.TracingConfig{Exporter: ExporterStdout, SampleRatio: 1}}, out)
+		s.NoError(err)
+
+		_, span := Tracer().Start(context.Background(), "written span")
+		span.End()
+		s.NoError(shutdown(context.Background()))
+
+		s.Contains(out.String(), `"Name":"written span"`)
+	})
+
+	s.T().Run("TestTracingGivenUnknownExporterWhenItIsSetUpThenItShouldReturnAnError", func(t *testing.T) {
+		_, err := Setup(context.Background(), model.Config{Tracing: model.TracingConfig{Exporter: "zipkin"}}, nil)
+		s.Error(err)
+	})
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/user"
+)
+
+// tracedUserDB records a span for every call made to the wrapped user.DB.
+type tracedUserDB struct {
+	db     user.DB
+	tracer trace.Tracer
+}
+
+// TraceUserDB returns db with each of its methods recorded as a
+// "user.DB/<method>" span.
+func TraceUserDB(tracer trace.Tracer, db user.DB) user.DB {
+	return &tracedUserDB{db: db, tracer: tracer}
+}
+
+func (t *tracedUserDB) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "user.DB/"+method, trace.WithSpanKind(trace.SpanKindClient))
+}
+
+func (t *tracedUserDB) GetByUsername(ctx context.Context, username string) (u *model.User, err error) {
+	ctx, span := t.start(ctx, "GetByUsername")
+	defer func() { endWith(span, err, user.StatusCode) }()
+	return t.db.GetByUsername(ctx, username)
+}
+
+func (t *tracedUserDB) Create(ctx context.Context, u *model.User) (created *model.User, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer func() { endWith(span, err, user.StatusCode) }()
+	return t.db.Create(ctx, u)
+}
+
+// tracedUserService records a span for every call made to the wrapped
+// user.Service, except Log. Usernames are left out of the spans.
+type tracedUserService struct {
+	service user.Service
+	tracer  trace.Tracer
+}
+
+// TraceUserService returns service with each of its methods recorded as a
+// "UserService/<method>" span.
+func TraceUserService(tracer trace.Tracer, service user.Service) user.Service {
+	return &tracedUserService{service: service, tracer: tracer}
+}
+
+func (t *tracedUserService) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "UserService/"+method)
+}
+
+func (t *tracedUserService) Register(ctx context.Context, credentials model.Credentials) (u *model.User, err error) {
+	ctx, span := t.start(ctx, "Register")
+	defer func() {
+		if u != nil {
+			span.SetAttributes(attribute.Int("user.id", u.ID))
+		}
+		endWith(span, err, user.StatusCode)
+	}()
+	return t.service.Register(ctx, credentials)
+}
+
+func (t *tracedUserService) Login(ctx context.Context, credentials model.Credentials) (token model.Token, err error) {
+	ctx, span := t.start(ctx, "Login")
+	defer func() { endWith(span, err, user.StatusCode) }()
+	return t.service.Login(ctx, credentials)
+}
+
+func (t *tracedUserService) Log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	t.service.Log(ctx, level, msg, args...)
+}
@@ -0,0 +1,28 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+)
+
+var (
+	ErrNotFound           = errors.New("no user found")
+	ErrUsernameTaken      = fmt.Errorf("%w: username is already taken", apperr.ErrConflict)
+	ErrInvalidCredentials = errors.New("Invalid Username Or Password")
+)
+
+// StatusCode maps an error returned by the user package to an HTTP status.
+// Shared errors fall back to apperr.StatusCode.
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return apperr.StatusCode(err)
+	}
+}
@@ -0,0 +1,72 @@
+package user
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/util"
+)
+
+type UserHandler struct {
+	us Service
+}
+
+type Handler interface {
+	Register(rw http.ResponseWriter, r *http.Request)
+	Login(rw http.ResponseWriter, r *http.Request)
+}
+
+func NewUserHandler(us Service) Handler {
+	return &UserHandler{
+		us: us,
+	}
+}
+
+func (uh *UserHandler) Register(rw http.ResponseWriter, r *http.Request) {
+	uh.us.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "Register")
+
+	credentials, err := decodeCredentials(rw, r)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, uh.us.Log)
+		return
+	}
+
+	user, err := uh.us.Register(r.Context(), credentials)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, uh.us.Log)
+		return
+	}
+
+	util.WriteResponse(rw, util.SetAndGetResponse(true, "User Registered Successfully", user, http.StatusOK))
+	uh.us.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "Register")
+}
+
+func (uh *UserHandler) Login(rw http.ResponseWriter, r *http.Request) {
+	uh.us.Log(r.Context(), slog.LevelDebug, "handling request", "handler", "Login")
+
+	credentials, err := decodeCredentials(rw, r)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, uh.us.Log)
+		return
+	}
+
+	token, err := uh.us.Login(r.Context(), credentials)
+	if err != nil {
+		apperr.WriteError(r.Context(), rw, err, StatusCode, uh.us.Log)
+		return
+	}
+
+	util.WriteResponse(rw, util.SetAndGetResponse(true, "User Logged In Successfully", token, http.StatusOK))
+	uh.us.Log(r.Context(), slog.LevelDebug, "request handled", "handler", "Login")
+}
+
+func decodeCredentials(rw http.ResponseWriter, r *http.Request) (model.Credentials, error) {
+	credentials := model.Credentials{}
+	if err := credentials.FromJSON(http.MaxBytesReader(rw, r.Body, apperr.MaxBodyBytes)); err != nil {
+		return credentials, apperr.BodyError(err)
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,72 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// Memory keeps accounts in a map until the process exits. Usernames are
+// unique regardless of case, and a mutex guards the map against concurrent
+// requests.
+type Memory struct {
+	mu     sync.RWMutex
+	lastID int
+	Users  map[int]*model.User
+}
+
+type DB interface {
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	Create(ctx context.Context, u *model.User) (*model.User, error)
+}
+
+func NewDB() DB {
+	return &Memory{
+		Users: make(map[int]*model.User),
+	}
+}
+
+// GetByUsername looks the user up case-insensitively.
+func (m *Memory) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.Users {
+		if strings.EqualFold(user.Username, username) {
+			c := *user
+			return &c, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (m *Memory) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.Users {
+		if strings.EqualFold(existing.Username, user.Username) {
+			return nil, ErrUsernameTaken
+		}
+	}
+
+	m.lastID++
+
+	stored := *user
+	stored.ID = m.lastID
+	m.Users[stored.ID] = &stored
+
+	created := stored
+	return &created, nil
+}
@@ -0,0 +1,104 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+const (
+	MinPasswordLength = 8
+	// MaxPasswordLength is the number of bytes bcrypt takes into account.
+	MaxPasswordLength = 72
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_.-]{3,32}$`)
+
+// dummyHash is compared against on logins for unknown usernames so they take
+// as long as logins with a wrong password and do not reveal which users exist.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+type UserService struct {
+	L      *slog.Logger
+	DB     DB
+	Tokens auth.Tokens
+}
+
+type Service interface {
+	Register(ctx context.Context, credentials model.Credentials) (*model.User, error)
+	Login(ctx context.Context, credentials model.Credentials) (model.Token, error)
+	Log(ctx context.Context, level slog.Level, msg string, args ...interface{})
+}
+
+func NewUserService(l *slog.Logger, db DB, tokens auth.Tokens) Service {
+	return UserService{
+		L:      l,
+		DB:     db,
+		Tokens: tokens,
+	}
+}
+
+// Register creates a user with a bcrypt hash of the password. Usernames are
+// case-insensitive and stored lower-cased.
+func (us UserService) Register(ctx context.Context, credentials model.Credentials) (*model.User, error) {
+	credentials.Username = strings.ToLower(strings.TrimSpace(credentials.Username))
+	if err := validateCredentials(credentials); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(credentials.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return us.DB.Create(ctx, &model.User{Username: credentials.Username, PasswordHash: string(hash)})
+}
+
+// Login checks the credentials and issues an access token for the user.
+func (us UserService) Login(ctx context.Context, credentials model.Credentials) (model.Token, error) {
+	user, err := us.DB.GetByUsername(ctx, strings.ToLower(strings.TrimSpace(credentials.Username)))
+	if errors.Is(err, ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(credentials.Password))
+		return model.Token{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.Token{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)); err != nil {
+		return model.Token{}, ErrInvalidCredentials
+	}
+
+	return us.Tokens.Issue(user)
+}
+
+// Log records an event of the account handlers along with the request ID
+// found in ctx.
+func (us UserService) Log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	us.L.Log(ctx, level, msg, args...)
+}
+
+func validateCredentials(credentials model.Credentials) error {
+	errs := apperr.ValidationErrors{}
+
+	if !usernamePattern.MatchString(credentials.Username) {
+		errs = append(errs, &apperr.ValidationError{Field: "username", Message: "must be 3 to 32 characters of a-z, 0-9, '_', '.' or '-'"})
+	}
+
+	if len(credentials.Password) < MinPasswordLength || len(credentials.Password) > MaxPasswordLength {
+		errs = append(errs, &apperr.ValidationError{Field: "password", Message: "must be 8 to 72 bytes long"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/database"
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// ServiceSuite runs the user service against every DB implementation.
+type ServiceSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	newDB   func(t *testing.T) DB
+	tokens  auth.Tokens
+	service Service
+}
+
+func TestMemoryServiceSuite(t *testing.T) {
+	suite.Run(t, &ServiceSuite{newDB: func(t *testing.T) DB {
+		return NewDB()
+	}})
+}
+
+func TestSQLiteServiceSuite(t *testing.T) {
+	suite.Run(t, &ServiceSuite{newDB: func(t *testing.T) DB {
+		db, err := database.Open(database.DriverSQLite, filepath.Join(t.TempDir(), "todo.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		require.NoError(t, database.Migrate(context.Background(), db, database.DriverSQLite))
+
+		return NewSQLStore(db, database.DriverSQLite)
+	}})
+}
+
+func (s *ServiceSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	tokens, err := auth.NewJWT(model.Config{TokenTTL: time.Hour, JWTSecret: "user-test-secret-of-at-least-32-bytes"})
+	s.NoError(err)
+	s.tokens = tokens
+	s.service = NewUserService(logging.Discard(), s.newDB(s.T()), s.tokens)
+}
+
+func (s *ServiceSuite) TestServiceGivenWhenRegisterIsCalled() {
+	s.T().Run("TestServiceGivenValidCredentialsWhenRegisterIsCalledThenItShouldStoreAHashedPassword", func(t *testing.T) {
+		user, err := s.service.Register(context.Background(), model.Credentials{Username: " Alice ", Password: "correct horse"})
+
+		s.NoError(err)
+		s.Greater(user.ID, 0)
+		s.Equal("alice", user.Username)
+		s.NotEqual("correct horse", user.PasswordHash)
+	})
+
+	s.T().Run("TestServiceGivenTakenUsernameInAnotherCaseWhenRegisterIsCalledThenItShouldReturnErrUsernameTaken", func(t *testing.T) {
+		_, err := s.service.Register(context.Background(), model.Credentials{Username: "ALICE", Password: "another horse"})
+
+		s.ErrorIs(err, ErrUsernameTaken)
+		s.Equal(http.StatusConflict, StatusCode(err))
+	})
+
+	s.T().Run("TestServiceGivenInvalidCredentialsWhenRegisterIsCalledThenItShouldReportEveryInvalidField", func(t *testing.T) {
+		_, err := s.service.Register(context.Background(), model.Credentials{Username: "a!", Password: "short"})
+
+		s.ErrorIs(err, apperr.ErrValidation)
+		s.Len(apperr.ValidationDetails(err), 2)
+	})
+}
+
+func (s *ServiceSuite) TestServiceGivenWhenLoginIsCalled() {
+	registered, err := s.service.Register(context.Background(), model.Credentials{Username: "alice", Password: "correct horse"})
+	s.NoError(err)
+
+	s.T().Run("TestServiceGivenValidCredentialsWhenLoginIsCalledThenItShouldIssueATokenForTheUser", func(t *testing.T) {
+		token, err := s.service.Login(context.Background(), model.Credentials{Username: "Alice", Password: "correct horse"})
+		s.NoError(err)
+
+		identity, err := s.tokens.Verify(context.Background(), token.AccessToken)
+		s.NoError(err)
+		s.Equal(registered.ID, identity.UserID)
+	})
+
+	s.T().Run("TestServiceGivenWrongPasswordWhenLoginIsCalledThenItShouldReturnErrInvalidCredentials", func(t *testing.T) {
+		_, err := s.service.Login(context.Background(), model.Credentials{Username: "alice", Password: "wrong horse"})
+
+		s.ErrorIs(err, ErrInvalidCredentials)
+		s.Equal(http.StatusUnauthorized, StatusCode(err))
+	})
+
+	s.T().Run("TestServiceGivenUnknownUsernameWhenLoginIsCalledThenItShouldReturnErrInvalidCredentials", func(t *testing.T) {
+		_, err := s.service.Login(context.Background(), model.Credentials{Username: "bob", Password: "correct horse"})
+
+		s.ErrorIs(err, ErrInvalidCredentials)
+	})
+}
@@ -0,0 +1,68 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/database"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// SQLStore is a DB implementation backed by database/sql. Usernames are
+// stored lower-cased so the unique index makes them case-insensitive.
+type SQLStore struct {
+	DB     *sql.DB
+	Driver string
+}
+
+func NewSQLStore(db *sql.DB, driver string) *SQLStore {
+	return &SQLStore{DB: db, Driver: driver}
+}
+
+func (s *SQLStore) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	user := &model.User{}
+	err := s.DB.QueryRowContext(ctx, database.Rebind(s.Driver, "SELECT id, username, password_hash FROM users WHERE username = LOWER(?)"), username).
+		Scan(&user.ID, &user.Username, &user.PasswordHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *SQLStore) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	created := &model.User{Username: user.Username, PasswordHash: user.PasswordHash}
+
+	err := s.DB.QueryRowContext(ctx, database.Rebind(s.Driver, "INSERT INTO users (username, password_hash) VALUES (LOWER(?), ?) RETURNING id, username"), user.Username, user.PasswordHash).
+		Scan(&created.ID, &created.Username)
+	if isUniqueViolation(err) {
+		return nil, ErrUsernameTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+
+	return false
+}
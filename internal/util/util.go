@@ -1,6 +1,11 @@
 package util
 
-import "github.com/tarkanaciksoz/api-todo-app/internal/model"
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
 
 func SetAndGetResponse(success bool, message string, data interface{}, code int) model.Response {
 	return model.Response{Success: success, Message: message, Data: data, Code: code}
@@ -13,3 +18,9 @@ func SetAndGetTodoResponse(success bool, message string, data *model.Todo, code
 func SetAndGetTodosResponse(success bool, message string, data model.Todos, code int) model.GetTodosResponse {
 	return model.GetTodosResponse{Success: success, Message: message, Data: data, Code: code}
 }
+
+// WriteResponse writes response as JSON using its Code as the HTTP status.
+func WriteResponse(rw http.ResponseWriter, response model.HTTPResponse) {
+	rw.WriteHeader(response.StatusCode())
+	json.NewEncoder(rw).Encode(response)
+}
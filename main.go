@@ -2,58 +2,144 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/tarkanaciksoz/api-todo-app/config"
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/metrics"
+	"github.com/tarkanaciksoz/api-todo-app/internal/storage"
+	"github.com/tarkanaciksoz/api-todo-app/internal/tracing"
+	"github.com/tarkanaciksoz/api-todo-app/internal/user"
 	"github.com/tarkanaciksoz/api-todo-app/pkg/server"
 )
 
+// flushTimeout bounds the export of the spans still buffered at exit.
+const flushTimeout = 5 * time.Second
+
 func main() {
-	logger := log.New(os.Stdout, "api-todo-app: ", log.LstdFlags)
-	if err := run(logger); err != nil {
-		logger.Printf("error - server failed to start. err: %v", err)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With("app", "api-todo-app")
+
+	// The first SIGINT or SIGTERM starts a graceful shutdown; stop restores the
+	// default behaviour so a second one kills the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := run(ctx, logger, os.Args[1:])
+	stop()
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
 	}
 }
 
-func run(logger *log.Logger) error {
-	config := config.Init(logger)
+// run serves the API until ctx is done, then drains in-flight requests and
+// releases the storage and the tracer. Only real failures are returned.
+func run(ctx context.Context, logger *slog.Logger, args []string) (err error) {
+	loaded, err := config.Load(args, os.Environ())
+	if err != nil {
+		return err
+	}
+	if loaded.PrintConfig {
+		return loaded.Print(os.Stdout)
+	}
+	config := loaded.Config
 
-	s := http.Server{
-		Addr:         config.BindAddress,
-		Handler:      server.Init(logger),
-		ErrorLog:     logger,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  120 * time.Second,
+	logger, err = logging.New(os.Stdout, config.LogLevel, config.LogFormat)
+	if err != nil {
+		return err
 	}
+	logger = logger.With("app", "api-todo-app", "env", config.AppEnv)
 
-	go func() {
-		logger.Printf("Starting server on port %s\n", ":9090")
+	shutdownTracing, err := tracing.Setup(context.Background(), config, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		defer cancel()
+		if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+			err = errors.Join(err, fmt.Errorf("flushing spans: %w", flushErr))
+		}
+	}()
 
-		err := s.ListenAndServe()
-		if err != nil {
-			logger.Printf("Error starting server: %s\n", err)
-			os.Exit(1)
+	store, err := storage.Open(config, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("closing storage: %w", closeErr))
 		}
 	}()
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	signal.Notify(c, os.Kill)
+	if err := store.Migrate(ctx); err != nil {
+		return err
+	}
+
+	if config.ClaimOrphanedTodos != "" {
+		// The user can only register once the server is up, so a missing one
+		// must not keep it from starting; the claim is retried on restart.
+		claimed, err := store.ClaimOrphanedTodos(ctx, config.ClaimOrphanedTodos)
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			logger.Warn("orphaned todos left unclaimed, the user is not registered yet", "username", config.ClaimOrphanedTodos)
+		case err != nil:
+			return err
+		default:
+			logger.Info("claimed orphaned todos", "username", config.ClaimOrphanedTodos, "todos", claimed)
+		}
+	}
+
+	health := server.NewHealth(logger)
+	m := metrics.New()
+	router, err := server.Init(logger, config, store, health, m)
+	if err != nil {
+		return err
+	}
+
+	// The internal server is closed once the API one drained, so metrics and
+	// probes cover the whole shutdown.
+	if config.InternalBindAddress != "" {
+		internal := server.NewInternalServer(logger, config, server.NewInternalHandler(m, health))
+		internalListener, err := net.Listen("tcp", internal.Addr)
+		if err != nil {
+			return err
+		}
+		defer internal.Close()
+
+		go func() {
+			if err := internal.Serve(internalListener); !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("internal server failed", "error", err)
+			}
+		}()
+		logger.Info("Starting internal server", "address", internalListener.Addr().String())
+	}
 
-	sig := <-c
-	log.Println("Got signal:", sig)
+	s, err := server.NewHTTPServer(logger, config, router)
+	if err != nil {
+		return err
+	}
 
-	ctx, _ := context.WithTimeout(context.Background(), 30*time.Second)
-	err := s.Shutdown(ctx)
+	listener, err := net.Listen("tcp", s.Addr)
 	if err != nil {
-		logger.Printf("Shutdown problem: %s\n", err.Error())
 		return err
 	}
 
-	return nil
+	logger.Info("Starting server", "address", listener.Addr().String(), "tls", s.TLSConfig != nil, "http2", config.Server.HTTP2)
+	return server.Serve(ctx, logger, s, listener, health, config)
 }
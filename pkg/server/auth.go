@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/util"
+)
+
+// APIKeyAuthentication authenticates machine clients sending an
+// "Authorization: ApiKey <key>" header and puts the identity of the key into
+// the request context. Requests using another scheme pass through untouched.
+func APIKeyAuthentication(keys auth.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			scheme, key, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !strings.EqualFold(scheme, "ApiKey") {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			identity, ok := verify(rw, r, keys, key)
+			if !ok {
+				return
+			}
+
+			next.ServeHTTP(rw, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+// Authenticate rejects requests without a valid "Authorization: Bearer"
+// token and puts the identity of the caller into the request context. Why a
+// token was rejected is not told to the client. Requests already
+// authenticated by APIKeyAuthentication pass through.
+func Authenticate(tokens auth.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if _, ok := auth.FromContext(r.Context()); ok {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !strings.EqualFold(scheme, "Bearer") {
+				unauthorized(rw, "Authentication Required")
+				return
+			}
+
+			identity, ok := verify(rw, r, tokens, token)
+			if !ok {
+				return
+			}
+
+			next.ServeHTTP(rw, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+// Authorize answers 403 to callers whose identity does not allow scope.
+func Authorize(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if identity, _ := auth.FromContext(r.Context()); !identity.Allows(scope) {
+				util.WriteResponse(rw, util.SetAndGetResponse(false, "Insufficient Scope, "+scope+" Required", nil, http.StatusForbidden))
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// verify writes the error response itself and reports false when credential
+// does not authenticate the request.
+func verify(rw http.ResponseWriter, r *http.Request, verifier auth.Verifier, credential string) (auth.Identity, bool) {
+	if credential == "" {
+		unauthorized(rw, "Authentication Required")
+		return auth.Identity{}, false
+	}
+
+	identity, err := verifier.Verify(r.Context(), credential)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		unauthorized(rw, auth.ErrInvalidToken.Error())
+		return auth.Identity{}, false
+	}
+	if err != nil {
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Internal Server Error", nil, http.StatusInternalServerError))
+		return auth.Identity{}, false
+	}
+
+	return identity, true
+}
+
+func unauthorized(rw http.ResponseWriter, message string) {
+	rw.Header().Add("WWW-Authenticate", `Bearer realm="todo-app-api"`)
+	rw.Header().Add("WWW-Authenticate", `ApiKey realm="todo-app-api"`)
+	util.WriteResponse(rw, util.SetAndGetResponse(false, message, nil, http.StatusUnauthorized))
+}
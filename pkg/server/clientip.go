@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies are the reverse proxies whose X-Forwarded-For header is
+// believed when working out the address of a client.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies accepts CIDR ranges and single IP addresses.
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := TrustedProxies{}
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", value)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+// ClientIP returns the address of the client that sent r. X-Forwarded-For is
+// only consulted when the request came from a trusted proxy, and is walked
+// from the right so clients cannot spoof it by sending their own header.
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if !p.trusts(ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !p.trusts(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (p TrustedProxies) trusts(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range p {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// CORS applies the cross-origin policy of model.CORSConfig to every response
+// and answers preflight requests for every route before they are routed.
+//
+// Allowed origins are matched case-insensitively. "*" alone allows any
+// origin; inside a pattern it stands for one host label or a port, so
+// "https://*.example.com" and "http://localhost:*" work as expected.
+type CORS struct {
+	anyOrigin   bool
+	origins     []*regexp.Regexp
+	methods     string
+	headers     string
+	anyHeader   bool
+	exposed     string
+	maxAge      string
+	credentials bool
+}
+
+func NewCORS(config model.CORSConfig) (*CORS, error) {
+	c := &CORS{
+		methods:     strings.Join(config.AllowedMethods, ", "),
+		headers:     strings.Join(config.AllowedHeaders, ", "),
+		exposed:     strings.Join(config.ExposedHeaders, ", "),
+		credentials: config.AllowCredentials,
+	}
+
+	if config.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+
+	for _, header := range config.AllowedHeaders {
+		c.anyHeader = c.anyHeader || header == "*"
+	}
+
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+			continue
+		}
+
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(origin)), `\*`, `[a-z0-9-]+`)
+		c.origins = append(c.origins, regexp.MustCompile("^"+pattern+"$"))
+	}
+
+	if c.anyOrigin && c.credentials {
+		return nil, errors.New("CORS credentials cannot be allowed together with every origin")
+	}
+
+	return c, nil
+}
+
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if origin == "" || !c.allows(origin) {
+			if preflight {
+				rw.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		if c.anyOrigin {
+			rw.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			rw.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.credentials {
+			rw.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if c.exposed != "" {
+				rw.Header().Set("Access-Control-Expose-Headers", c.exposed)
+			}
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		rw.Header().Add("Vary", "Access-Control-Request-Method")
+		rw.Header().Add("Vary", "Access-Control-Request-Headers")
+		rw.Header().Set("Access-Control-Allow-Methods", c.methods)
+		if c.anyHeader {
+			rw.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		} else if c.headers != "" {
+			rw.Header().Set("Access-Control-Allow-Headers", c.headers)
+		}
+		if c.maxAge != "" {
+			rw.Header().Set("Access-Control-Max-Age", c.maxAge)
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (c *CORS) allows(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	for _, pattern := range c.origins {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+type CORSSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	config model.CORSConfig
+	next   http.Handler
+}
+
+func TestCORSSuite(t *testing.T) {
+	suite.Run(t, new(CORSSuite))
+}
+
+func (s *CORSSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.config = model.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org", "http://localhost:*"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		MaxAge:           10 * time.Minute,
+		AllowCredentials: true,
+	}
+	s.next = http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	})
+}
+
+func (s *CORSSuite) serve(config model.CORSConfig, r *http.Request) *http.Response {
+	cors, err := NewCORS(config)
+	s.NoError(err)
+
+	w := httptest.NewRecorder()
+	cors.Handler(s.next).ServeHTTP(w, r)
+	return w.Result()
+}
+
+func preflight(origin string) *http.Request {
+	r := httptest.NewRequest(http.MethodOptions, "/anything", nil)
+	r.Header.Set("Origin", origin)
+	r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	r.Header.Set("Access-Control-Request-Headers", "authorization")
+	return r
+}
+
+func (s *CORSSuite) TestCORSGivenWhenAPreflightIsServed() {
+	s.T().Run("TestCORSGivenAllowedOriginWhenAPreflightIsServedThenItShouldAnswer204WithThePolicy", func(t *testing.T) {
+		result := s.serve(s.config, preflight("https://app.example.com"))
+
+		s.Equal(http.StatusNoContent, result.StatusCode)
+		s.Equal("https://app.example.com", result.Header.Get("Access-Control-Allow-Origin"))
+		s.Equal("true", result.Header.Get("Access-Control-Allow-Credentials"))
+		s.Equal("GET, POST", result.Header.Get("Access-Control-Allow-Methods"))
+		s.Equal("Authorization, Content-Type", result.Header.Get("Access-Control-Allow-Headers"))
+		s.Equal("600", result.Header.Get("Access-Control-Max-Age"))
+		s.Contains(result.Header.Values("Vary"), "Origin")
+	})
+
+	s.T().Run("TestCORSGivenOriginMatchingAPatternWhenAPreflightIsServedThenTheOriginShouldBeReflected", func(t *testing.T) {
+		for _, origin := range []string{"https://todo.example.org", "HTTP://LOCALHOST:3000"} {
+			result := s.serve(s.config, preflight(origin))
+
+			s.Equal(origin, result.Header.Get("Access-Control-Allow-Origin"))
+		}
+	})
+
+	s.T().Run("TestCORSGivenOriginNotMatchingAnyPatternWhenAPreflightIsServedThenItShouldNotAllowIt", func(t *testing.T) {
+		for _, origin := range []string{"https://evil.com", "https://app.example.com.evil.com", "https://a.b.example.org", "https://example.org"} {
+			result := s.serve(s.config, preflight(origin))
+
+			s.Equal(http.StatusNoContent, result.StatusCode)
+			s.Empty(result.Header.Get("Access-Control-Allow-Origin"), origin)
+		}
+	})
+
+	s.T().Run("TestCORSGivenAnyHeaderAllowedWhenAPreflightIsServedThenTheRequestedHeadersShouldBeAllowed", func(t *testing.T) {
+		config := s.config
+		config.AllowedHeaders = []string{"*"}
+
+		result := s.serve(config, preflight("https://app.example.com"))
+
+		s.Equal("authorization", result.Header.Get("Access-Control-Allow-Headers"))
+	})
+}
+
+func (s *CORSSuite) TestCORSGivenWhenARequestIsServed() {
+	s.T().Run("TestCORSGivenAllowedOriginWhenARequestIsServedThenItShouldReachTheHandlerWithCORSHeaders", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/anything", nil)
+		r.Header.Set("Origin", "https://app.example.com")
+
+		result := s.serve(s.config, r)
+
+		s.Equal(http.StatusTeapot, result.StatusCode)
+		s.Equal("https://app.example.com", result.Header.Get("Access-Control-Allow-Origin"))
+		s.Equal("X-Request-ID", result.Header.Get("Access-Control-Expose-Headers"))
+	})
+
+	s.T().Run("TestCORSGivenAnyOriginWithoutCredentialsWhenARequestIsServedThenTheWildcardShouldBeSent", func(t *testing.T) {
+		config := s.config
+		config.AllowedOrigins = []string{"*"}
+		config.AllowCredentials = false
+		r := httptest.NewRequest(http.MethodGet, "/anything", nil)
+		r.Header.Set("Origin", "https://anyone.com")
+
+		result := s.serve(config, r)
+
+		s.Equal("*", result.Header.Get("Access-Control-Allow-Origin"))
+		s.Empty(result.Header.Get("Access-Control-Allow-Credentials"))
+	})
+
+	s.T().Run("TestCORSGivenNoOriginWhenARequestIsServedThenNoCORSHeadersShouldBeSent", func(t *testing.T) {
+		result := s.serve(s.config, httptest.NewRequest(http.MethodGet, "/anything", nil))
+
+		s.Equal(http.StatusTeapot, result.StatusCode)
+		s.Empty(result.Header.Get("Access-Control-Allow-Origin"))
+	})
+
+	s.T().Run("TestCORSGivenAnyOriginWithCredentialsWhenItIsBuiltThenItShouldReturnAnError", func(t *testing.T) {
+		config := s.config
+		config.AllowedOrigins = []string{"*"}
+
+		_, err := NewCORS(config)
+
+		s.Error(err)
+	})
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/util"
+)
+
+// checkTimeout bounds every readiness check so that a hanging dependency
+// fails the probe instead of outliving the load balancer's own timeout.
+const checkTimeout = 2 * time.Second
+
+const (
+	checkOK      = "ok"
+	checkFailing = "failing"
+)
+
+// Check reports whether a dependency can currently serve requests.
+type Check func(ctx context.Context) error
+
+// Health answers liveness and readiness probes. The server is ready while
+// every check passes and Shutdown has not been called. Check errors are only
+// logged; probes are public so responses just name the failing dependencies.
+type Health struct {
+	L *slog.Logger
+
+	mu           sync.RWMutex
+	checks       map[string]Check
+	shuttingDown atomic.Bool
+}
+
+func NewHealth(l *slog.Logger) *Health {
+	return &Health{
+		L:      l,
+		checks: make(map[string]Check),
+	}
+}
+
+// AddCheck makes readiness depend on check, reported under name.
+func (h *Health) AddCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks[name] = check
+}
+
+// Shutdown marks the server as not ready for good so load balancers stop
+// sending new requests while in-flight ones drain.
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// Live answers 200 as long as the process can serve requests at all.
+func (h *Health) Live(rw http.ResponseWriter, r *http.Request) {
+	util.WriteResponse(rw, util.SetAndGetResponse(true, "Alive", nil, http.StatusOK))
+}
+
+// Ready answers 200 when every check passes and 503 otherwise, with the state
+// of each check in data.
+func (h *Health) Ready(rw http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Shutting Down", nil, http.StatusServiceUnavailable))
+		return
+	}
+
+	results, ready := h.run(r.Context())
+	if !ready {
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Not Ready", results, http.StatusServiceUnavailable))
+		return
+	}
+
+	util.WriteResponse(rw, util.SetAndGetResponse(true, "Ready", results, http.StatusOK))
+}
+
+// run executes every check concurrently and returns their states by name.
+func (h *Health) run(ctx context.Context) (map[string]string, bool) {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = h.checks[name]
+	}
+	h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			errs[i] = check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	results, ready := make(map[string]string, len(names)), true
+	for i, name := range names {
+		if errs[i] != nil {
+			h.L.WarnContext(ctx, "readiness check failed", "check", name, "error", errs[i])
+			results[name], ready = checkFailing, false
+			continue
+		}
+		results[name] = checkOK
+	}
+
+	return results, ready
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+type HealthSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	health *Health
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(HealthSuite))
+}
+
+func (s *HealthSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.health = NewHealth(logging.Discard())
+	s.health.AddCheck("database", func(context.Context) error { return nil })
+}
+
+func (s *HealthSuite) serve(handler http.HandlerFunc) (*http.Response, model.Response) {
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	response := model.Response{}
+	s.NoError(json.NewDecoder(w.Result().Body).Decode(&response))
+	return w.Result(), response
+}
+
+func (s *HealthSuite) TestHealthGivenWhenReadinessIsProbed() {
+	s.T().Run("TestHealthGivenPassingChecksWhenReadinessIsProbedThenTheStatusShouldBe200WithEveryCheck", func(t *testing.T) {
+		result, response := s.serve(s.health.Ready)
+
+		s.Equal(http.StatusOK, result.StatusCode)
+		s.True(response.Success)
+		s.Equal(map[string]interface{}{"database": "ok"}, response.Data)
+	})
+
+	s.T().Run("TestHealthGivenFailingCheckWhenReadinessIsProbedThenTheStatusShouldBe503NamingItWithoutTheError", func(t *testing.T) {
+		s.health.AddCheck("cache", func(context.Context) error { return errors.New("dial tcp 10.0.0.1:6379: connection refused") })
+
+		result, response := s.serve(s.health.Ready)
+
+		s.Equal(http.StatusServiceUnavailable, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+		s.False(response.Success)
+		s.Equal(map[string]interface{}{"database": "ok", "cache": "failing"}, response.Data)
+	})
+
+	s.T().Run("TestHealthGivenShutdownWhenReadinessIsProbedThenTheStatusShouldBe503EvenIfChecksPass", func(t *testing.T) {
+		s.health = NewHealth(logging.Discard())
+		s.health.AddCheck("database", func(context.Context) error { return nil })
+		s.health.Shutdown()
+
+		result, response := s.serve(s.health.Ready)
+
+		s.Equal(http.StatusServiceUnavailable, result.StatusCode)
+		s.Equal("Shutting Down", response.Message)
+	})
+}
+
+func (s *HealthSuite) TestHealthGivenWhenLivenessIsProbed() {
+	s.T().Run("TestHealthGivenShutdownWhenLivenessIsProbedThenTheStatusShouldStillBe200", func(t *testing.T) {
+		s.health.Shutdown()
+
+		result, response := s.serve(s.health.Live)
+
+		s.Equal(http.StatusOK, result.StatusCode)
+		s.True(response.Success)
+	})
+}
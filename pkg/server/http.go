@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// NewHTTPServer returns the server of handler tuned by config.Server. Serve
+// speaks TLS when the returned server has a TLSConfig.
+func NewHTTPServer(logger *slog.Logger, config model.Config, handler http.Handler) (*http.Server, error) {
+	tlsConfig, err := NewTLSConfig(config.Server.TLS, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &http.Server{
+		Addr:              config.BindAddress,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		ReadTimeout:       config.Server.ReadTimeout,
+		ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
+		WriteTimeout:      config.Server.WriteTimeout,
+		IdleTimeout:       config.Server.IdleTimeout,
+		MaxHeaderBytes:    config.Server.MaxHeaderBytes,
+		TLSConfig:         tlsConfig,
+	}
+
+	switch {
+	case !config.Server.HTTP2:
+		// A non-nil empty map keeps net/http from offering h2 with ALPN.
+		s.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	case tlsConfig == nil:
+		// Without TLS there is no ALPN, so HTTP/2 clients must use h2c. h2c
+		// hijacks its connections, so Shutdown of s does not reach them:
+		// configuring a bare server gives the hook telling them to go away
+		// once their streams complete, and Serve waits for them to close,
+		// about a second later for idle ones.
+		h2 := &http2.Server{IdleTimeout: config.Server.IdleTimeout}
+		goAway := &http.Server{}
+		if err := http2.ConfigureServer(goAway, h2); err != nil {
+			return nil, err
+		}
+		s.RegisterOnShutdown(func() { goAway.Shutdown(context.Background()) })
+		handler = h2c.NewHandler(handler, h2)
+	}
+	s.Handler = handler
+
+	return s, nil
+}
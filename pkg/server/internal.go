@@ -0,0 +1,37 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/metrics"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// NewInternalHandler serves the metrics of m and the probes of health. It
+// belongs on the internal listener, reachable by monitoring but not by API
+// clients. Being plaintext, it lets probes skip the TLS settings of the API.
+func NewInternalHandler(m *metrics.Metrics, health *Health) http.Handler {
+	router := mux.NewRouter()
+	router.Handle("/metrics", m.Handler()).Methods(http.MethodGet)
+	router.HandleFunc("/healthz", health.Live).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", health.Ready).Methods(http.MethodGet)
+
+	return router
+}
+
+// NewInternalServer returns the plaintext server of handler listening on
+// config.InternalBindAddress, with the timeouts of the API server.
+func NewInternalServer(logger *slog.Logger, config model.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              config.InternalBindAddress,
+		Handler:           handler,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		ReadTimeout:       config.Server.ReadTimeout,
+		ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
+		WriteTimeout:      config.Server.WriteTimeout,
+		IdleTimeout:       config.Server.IdleTimeout,
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// Serve serves srv on listener, over TLS when srv.TLSConfig is set, until ctx
+// is done and then shuts it down gracefully:
+//
+//  1. health reports the server as not ready,
+//  2. requests keep being served for config.ShutdownDelay so load balancers
+//     notice and stop routing new traffic,
+//  3. the listener is closed and in-flight requests get up to
+//     config.ShutdownTimeout to complete, including those of hijacked
+//     connections such as h2c ones, which Shutdown does not wait for.
+//
+// It returns nil once every request completed, and an error when serving
+// failed or requests had to be cut off.
+func Serve(ctx context.Context, logger *slog.Logger, srv *http.Server, listener net.Listener, health *Health, config model.Config) error {
+	conns := &trackingListener{Listener: listener, conns: make(map[*trackedConn]struct{})}
+	listener = conns
+
+	served := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			served <- srv.ServeTLS(listener, "", "")
+			return
+		}
+		served <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		// Serve only returns http.ErrServerClosed after Shutdown or Close,
+		// which nothing else calls, so any return here is a failure.
+		return fmt.Errorf("serving: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down", "delay", config.ShutdownDelay, "timeout", config.ShutdownTimeout)
+	health.Shutdown()
+
+	if config.ShutdownDelay > 0 {
+		timer := time.NewTimer(config.ShutdownDelay)
+		defer timer.Stop()
+
+		select {
+		case err := <-served:
+			return fmt.Errorf("serving: %w", err)
+		case <-timer.C:
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	err := srv.Shutdown(shutdownCtx)
+	if err == nil {
+		// Every connection left once Shutdown returns has been hijacked.
+		err = conns.wait(shutdownCtx)
+	}
+	if err != nil {
+		srv.Close()
+		conns.closeAll()
+		return fmt.Errorf("draining in-flight requests: %w", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving: %w", err)
+	}
+
+	logger.Info("server stopped")
+	return nil
+}
+
+// drainPollInterval is how often Serve checks whether hijacked connections
+// have closed, as http.Server.Shutdown does for its own.
+const drainPollInterval = 10 * time.Millisecond
+
+// trackingListener keeps the connections it accepted until they are closed,
+// so that Serve can wait for the ones http.Server forgets once hijacked.
+type trackingListener struct {
+	net.Listener
+
+	mu    sync.Mutex
+	conns map[*trackedConn]struct{}
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := &trackedConn{Conn: conn, listener: l}
+	l.mu.Lock()
+	l.conns[tracked] = struct{}{}
+	l.mu.Unlock()
+
+	return tracked, nil
+}
+
+// wait returns once every accepted connection is closed, or the error of ctx
+// when it is done first.
+func (l *trackingListener) wait(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		l.mu.Lock()
+		open := len(l.conns)
+		l.mu.Unlock()
+		if open == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *trackingListener) closeAll() {
+	l.mu.Lock()
+	conns := make([]*trackedConn, 0, len(l.conns))
+	for conn := range l.conns {
+		conns = append(conns, conn)
+	}
+	l.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+type trackedConn struct {
+	net.Conn
+
+	listener *trackingListener
+	once     sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.listener.mu.Lock()
+		delete(c.listener.conns, c)
+		c.listener.mu.Unlock()
+	})
+
+	return c.Conn.Close()
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/http2"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/metrics"
+	"github.com/tarkanaciksoz/api-todo-app/internal/storage"
+)
+
+// LifecycleSuite serves the real router on a TCP listener and shuts it down
+// while a request is in flight.
+type LifecycleSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	health   *Health
+	srv      *http.Server
+	listener net.Listener
+	url      string
+
+	entered chan struct{}
+	release chan struct{}
+}
+
+func TestLifecycleSuite(t *testing.T) {
+	suite.Run(t, new(LifecycleSuite))
+}
+
+func (s *LifecycleSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.health = NewHealth(logging.Discard())
+
+	router, err := Init(logging.Discard(), testConfig, storage.NewMemory(), s.health, metrics.New())
+	s.NoError(err)
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	s.entered, s.release = entered, release
+	mux := http.NewServeMux()
+	mux.Handle("/", router)
+	mux.HandleFunc("/slow", func(rw http.ResponseWriter, _ *http.Request) {
+		close(entered)
+		<-release
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	s.NoError(err)
+	s.url = "http://" + s.listener.Addr().String()
+	s.srv = &http.Server{Handler: mux}
+}
+
+// serve runs Serve in the background and returns its result channel.
+func (s *LifecycleSuite) serve(ctx context.Context, delay, timeout time.Duration) <-chan error {
+	config := testConfig
+	config.ShutdownDelay, config.ShutdownTimeout = delay, timeout
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, logging.Discard(), s.srv, s.listener, s.health, config)
+	}()
+	return done
+}
+
+// startSlowRequest sends a request that only completes once s.release is
+// closed and waits for the handler to be running.
+func (s *LifecycleSuite) startSlowRequest() <-chan *http.Response {
+	return s.startSlowRequestWith(http.DefaultClient)
+}
+
+func (s *LifecycleSuite) startSlowRequestWith(client *http.Client) <-chan *http.Response {
+	responses := make(chan *http.Response, 1)
+	go func() {
+		response, err := client.Get(s.url + "/slow")
+		if err != nil {
+			responses <- nil
+			return
+		}
+		io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+		responses <- response
+	}()
+
+	<-s.entered
+	return responses
+}
+
+func (s *LifecycleSuite) TestLifecycleGivenWhenTheServerIsShutDown() {
+	s.T().Run("TestLifecycleGivenInFlightRequestWhenTheServerIsShutDownThenItShouldCompleteAndServeShouldReturnNil", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := s.serve(ctx, 200*time.Millisecond, 5*time.Second)
+		responses := s.startSlowRequest()
+
+		cancel()
+
+		s.Eventually(func() bool {
+			response, err := http.Get(s.url + "/readyz")
+			if err != nil {
+				return false
+			}
+			response.Body.Close()
+			return response.StatusCode == http.StatusServiceUnavailable
+		}, time.Second, 10*time.Millisecond)
+
+		select {
+		case err := <-done:
+			s.Failf("Serve returned while a request was in flight", "error: %v", err)
+		case <-time.After(300 * time.Millisecond):
+		}
+
+		close(s.release)
+
+		response := <-responses
+		s.NotNil(response)
+		s.Equal(http.StatusOK, response.StatusCode)
+		s.NoError(<-done)
+
+		_, err := http.Get(s.url + "/healthz")
+		s.Error(err)
+	})
+}
+
+// serveH2C replaces the server with the one NewHTTPServer returns for
+// cleartext HTTP/2 and returns a client speaking h2c to it.
+func (s *LifecycleSuite) serveH2C() *http.Client {
+	config := testConfig
+	config.Server.HTTP2 = true
+
+	srv, err := NewHTTPServer(logging.Discard(), config, s.srv.Handler)
+	s.NoError(err)
+	s.srv = srv
+
+	return &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func (s *LifecycleSuite) TestLifecycleGivenWhenAnH2CServerIsShutDown() {
+	s.T().Run("TestLifecycleGivenInFlightH2CRequestWhenTheServerIsShutDownThenItShouldCompleteBeforeServeReturns", func(t *testing.T) {
+		client := s.serveH2C()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := s.serve(ctx, 0, 5*time.Second)
+		responses := s.startSlowRequestWith(client)
+
+		cancel()
+
+		select {
+		case err := <-done:
+			s.Failf("Serve returned while an h2c request was in flight", "error: %v", err)
+		case <-time.After(300 * time.Millisecond):
+		}
+
+		close(s.release)
+
+		response := <-responses
+		s.NotNil(response)
+		s.Equal(http.StatusOK, response.StatusCode)
+		s.Equal("HTTP/2.0", response.Proto)
+		s.NoError(<-done)
+	})
+}
+
+func (s *LifecycleSuite) TestLifecycleGivenWhenAnH2CRequestOutlivesTheTimeout() {
+	s.T().Run("TestLifecycleGivenH2CRequestOutlivingTheTimeoutWhenTheServerIsShutDownThenItsConnectionShouldBeClosed", func(t *testing.T) {
+		defer close(s.release)
+		client := s.serveH2C()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := s.serve(ctx, 0, 50*time.Millisecond)
+		responses := s.startSlowRequestWith(client)
+
+		cancel()
+
+		s.ErrorIs(<-done, context.DeadlineExceeded)
+		s.Nil(<-responses)
+	})
+}
+
+func (s *LifecycleSuite) TestLifecycleGivenWhenDrainingTimesOut() {
+	s.T().Run("TestLifecycleGivenRequestOutlivingTheTimeoutWhenTheServerIsShutDownThenServeShouldReturnAnError", func(t *testing.T) {
+		defer close(s.release)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := s.serve(ctx, 0, 50*time.Millisecond)
+		s.startSlowRequest()
+
+		cancel()
+
+		s.ErrorIs(<-done, context.DeadlineExceeded)
+	})
+}
+
+func (s *LifecycleSuite) TestLifecycleGivenWhenServingFails() {
+	s.T().Run("TestLifecycleGivenClosedListenerWhenItIsServedThenServeShouldReturnAnErrorWithoutWaitingForShutdown", func(t *testing.T) {
+		s.NoError(s.listener.Close())
+
+		err := <-s.serve(context.Background(), 0, time.Second)
+		s.ErrorIs(err, net.ErrClosed)
+	})
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits the request IDs accepted from clients to what is safe
+// to put in log lines and response headers.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID takes the ID of the request from the X-Request-ID header, or
+// generates one, and puts it into the request context and the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		rw.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(rw, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(raw)
+}
+
+// accessInfo is filled in by the handlers of a matched route so the access
+// log, which wraps the router, can tell which route served the request.
+type accessInfo struct {
+	route  string
+	userID int
+}
+
+type accessInfoKey struct{}
+
+// AccessLog writes one record per request with its method, path, route name,
+// status and latency. Server errors are logged at error level.
+func AccessLog(logger *slog.Logger, proxies TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info := &accessInfo{}
+			recorder := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), accessInfoKey{}, info)))
+
+			level := slog.LevelInfo
+			if recorder.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", info.route),
+				slog.Int("status", recorder.status),
+				slog.Int("bytes", recorder.bytes),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("client_ip", proxies.ClientIP(r)),
+			}
+			if info.userID != 0 {
+				attrs = append(attrs, slog.Int("user_id", info.userID))
+			}
+
+			logger.LogAttrs(r.Context(), level, "request served", attrs...)
+		})
+	}
+}
+
+// recordRoute tells the access log which route a request was matched to.
+func recordRoute(route model.Route) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if info, ok := r.Context().Value(accessInfoKey{}).(*accessInfo); ok {
+				info.route = route.Name
+			}
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// recordUser tells the access log which user a request was authenticated as.
+func recordUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		info, ok := r.Context().Value(accessInfoKey{}).(*accessInfo)
+		if identity, authenticated := auth.FromContext(r.Context()); ok && authenticated {
+			info.userID = identity.UserID
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/metrics"
+	"github.com/tarkanaciksoz/api-todo-app/internal/storage"
+)
+
+type LoggingSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	logs   *bytes.Buffer
+	router http.Handler
+}
+
+func TestLoggingSuite(t *testing.T) {
+	suite.Run(t, new(LoggingSuite))
+}
+
+func (s *LoggingSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+
+	s.logs = &bytes.Buffer{}
+	logger, err := logging.New(s.logs, slog.LevelInfo, logging.FormatJSON)
+	s.NoError(err)
+
+	s.router, err = Init(logger, testConfig, storage.NewMemory(), NewHealth(logger), metrics.New())
+	s.NoError(err)
+}
+
+// lines returns the log records written so far with the given message.
+func (s *LoggingSuite) lines(msg string) []map[string]interface{} {
+	lines := []map[string]interface{}{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(s.logs.Bytes()))
+	for scanner.Scan() {
+		line := map[string]interface{}{}
+		s.NoError(json.Unmarshal(scanner.Bytes(), &line))
+		if line["msg"] == msg {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func (s *LoggingSuite) TestLoggingGivenWhenARequestIsServed() {
+	s.T().Run("TestLoggingGivenRequestIDWhenARequestIsServedThenItShouldBeEchoedAndLogged", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/todo/1", nil)
+		r.Header.Set(RequestIDHeader, "req-42")
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, r)
+
+		s.Equal("req-42", w.Header().Get(RequestIDHeader))
+
+		access := s.lines("request served")
+		s.Len(access, 1)
+		s.Equal("req-42", access[0]["request_id"])
+		s.Equal("GET TODO", access[0]["route"])
+		s.Equal("GET", access[0]["method"])
+		s.Equal(float64(http.StatusUnauthorized), access[0]["status"])
+		s.Contains(access[0], "latency_ms")
+	})
+
+	s.T().Run("TestLoggingGivenInvalidRequestIDWhenARequestIsServedThenANewOneShouldBeGenerated", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/todo", nil)
+		r.Header.Set(RequestIDHeader, "bad id\nwith newline")
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, r)
+
+		s.Regexp("^[0-9a-f]{32}$", w.Header().Get(RequestIDHeader))
+	})
+
+	s.T().Run("TestLoggingGivenFailingRequestWhenItIsServedThenTheServiceLogShouldCarryTheRequestID", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"username":"nobody","password":"wrong password"}`))
+		r.Header.Set(RequestIDHeader, "req-43")
+
+		s.router.ServeHTTP(httptest.NewRecorder(), r)
+
+		failures := s.lines("request failed")
+		s.NotEmpty(failures)
+		s.Equal("req-43", failures[len(failures)-1]["request_id"])
+		s.Equal(float64(http.StatusUnauthorized), failures[len(failures)-1]["status"])
+	})
+
+	s.T().Run("TestLoggingGivenUnknownRouteWhenItIsServedThenTheAccessLogShouldHaveNoRouteName", func(t *testing.T) {
+		s.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+		access := s.lines("request served")
+		s.Equal("", access[len(access)-1]["route"])
+		s.Equal(float64(http.StatusNotFound), access[len(access)-1]["status"])
+	})
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/util"
+)
+
+// sweepInterval is how often buckets that have refilled completely, and so
+// behave exactly like missing ones, are dropped.
+const sweepInterval = time.Minute
+
+// RateLimiter throttles clients with one token bucket per route and client.
+// Clients are told apart by API key, then by user, then by IP address, so
+// that users behind one NAT do not share a budget once logged in. Failed
+// authentications are throttled by IP address before credentials are checked.
+type RateLimiter struct {
+	mu        sync.Mutex
+	config    model.RateLimitConfig
+	proxies   TrustedProxies
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	rate    float64
+	burst   float64
+}
+
+func NewRateLimiter(config model.RateLimitConfig, proxies TrustedProxies) *RateLimiter {
+	return &RateLimiter{
+		config:  config,
+		proxies: proxies,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Limit returns the middleware enforcing the limit configured for route, or
+// the default limit when the route has none. A limit without requests
+// disables rate limiting.
+func (l *RateLimiter) Limit(route model.Route) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		limit, ok := l.limit(route)
+		if !ok {
+			return next
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if l.allow(rw, route.Name+"|"+l.clientKey(r), limit, 1) {
+				next.ServeHTTP(rw, r)
+			}
+		})
+	}
+}
+
+// LimitFailedAuthentication returns the middleware applying the limit of
+// route to requests answered 401, keyed by IP address. It goes before the
+// authentication middlewares so that a client out of budget is rejected
+// without its credentials being looked up, while the valid requests of
+// clients sharing its address are not counted: the budget is only checked on
+// the way in and a token is taken once the response is 401. Concurrent
+// failures may therefore all be checked, but each of them is paid for, the
+// bucket going into debt if needed.
+func (l *RateLimiter) LimitFailedAuthentication(route model.Route) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		limit, ok := l.limit(route)
+		if !ok {
+			return next
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			key := "auth|" + route.Name + "|ip:" + l.proxies.ClientIP(r)
+			if !l.allow(rw, key, limit, 0) {
+				return
+			}
+
+			recorder := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+			if recorder.status == http.StatusUnauthorized {
+				l.charge(key, limit)
+			}
+		})
+	}
+}
+
+type routeLimit struct {
+	rate   float64
+	burst  int
+	policy string
+}
+
+func (l *RateLimiter) limit(route model.Route) (routeLimit, bool) {
+	limit, ok := l.config.Routes[route.Name]
+	if !ok {
+		limit = l.config.Default
+	}
+	if limit.Requests <= 0 || limit.Per <= 0 {
+		return routeLimit{}, false
+	}
+
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = limit.Requests
+	}
+
+	return routeLimit{
+		rate:   float64(limit.Requests) / limit.Per.Seconds(),
+		burst:  burst,
+		policy: strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(math.Ceil(limit.Per.Seconds()))),
+	}, true
+}
+
+// allow takes cost tokens from the bucket of key, when at least one is left,
+// and sets the rate limit headers. It answers 429 itself and reports false
+// when no token was left.
+func (l *RateLimiter) allow(rw http.ResponseWriter, key string, limit routeLimit, cost float64) bool {
+	allowed, remaining, reset, retryAfter := l.take(key, limit.rate, float64(limit.burst), cost)
+
+	rw.Header().Set("RateLimit-Policy", limit.policy)
+	rw.Header().Set("RateLimit-Limit", strconv.Itoa(limit.burst))
+	rw.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	rw.Header().Set("RateLimit-Reset", seconds(reset))
+
+	if !allowed {
+		rw.Header().Set("Retry-After", seconds(retryAfter))
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Too Many Requests", nil, http.StatusTooManyRequests))
+	}
+
+	return allowed
+}
+
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if identity, ok := auth.FromContext(r.Context()); ok {
+		if identity.APIKeyID != 0 {
+			return "key:" + strconv.Itoa(identity.APIKeyID)
+		}
+		return "user:" + strconv.Itoa(identity.UserID)
+	}
+
+	return "ip:" + l.proxies.ClientIP(r)
+}
+
+// take removes cost tokens from the bucket of key when at least one is left.
+// It reports the tokens left, how long until the bucket is full again and,
+// when no token was left, how long until the next one is available.
+func (l *RateLimiter) take(key string, rate float64, burst float64, cost float64) (bool, int, time.Duration, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b := l.bucket(key, now, rate, burst)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens -= cost
+	}
+
+	reset := time.Duration((b.burst - b.tokens) / b.rate * float64(time.Second))
+	var retryAfter time.Duration
+	if !allowed {
+		retryAfter = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+
+	return allowed, int(math.Max(0, b.tokens)), reset, retryAfter
+}
+
+// charge takes a token from the bucket of key even when none is left, so that
+// the next ones wait until the debt is paid back.
+func (l *RateLimiter) charge(key string, limit routeLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.bucket(key, l.now(), limit.rate, float64(limit.burst)).tokens--
+}
+
+// bucket returns the bucket of key, refilled up to now. l.mu must be held.
+func (l *RateLimiter) bucket(key string, now time.Time, rate float64, burst float64) *bucket {
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: burst, updated: now, rate: rate, burst: burst}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+
+	return b
+}
+
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.refill(now); b.tokens >= b.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.updated = now
+}
+
+// seconds formats d as whole seconds, rounding up so clients never retry early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
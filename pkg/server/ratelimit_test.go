@@ -0,0 +1,232 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+type RateLimiterSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	now     time.Time
+	limiter *RateLimiter
+	route   model.Route
+}
+
+func TestRateLimiterSuite(t *testing.T) {
+	suite.Run(t, new(RateLimiterSuite))
+}
+
+func (s *RateLimiterSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	s.NoError(err)
+
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.limiter = NewRateLimiter(model.RateLimitConfig{
+		Default: model.RateLimit{Requests: 60, Per: time.Minute},
+		Routes: map[string]model.RateLimit{
+			"CREATE NEW TODO": {Requests: 2, Per: time.Minute},
+		},
+	}, proxies)
+	s.limiter.now = func() time.Time { return s.now }
+	s.route = model.Route{Name: "CREATE NEW TODO"}
+}
+
+func (s *RateLimiterSuite) serve(route model.Route, r *http.Request) *http.Response {
+	w := httptest.NewRecorder()
+	s.limiter.Limit(route)(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, r)
+	return w.Result()
+}
+
+func request(remoteAddr string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/todo", nil)
+	r.RemoteAddr = remoteAddr
+	return r
+}
+
+func (s *RateLimiterSuite) TestRateLimiterGivenWhenARouteIsLimited() {
+	s.T().Run("TestRateLimiterGivenExhaustedBucketWhenARequestIsServedThenItShouldBe429WithRetryAfter", func(t *testing.T) {
+		for remaining := 1; remaining >= 0; remaining-- {
+			result := s.serve(s.route, request("192.0.2.1:1234"))
+			s.Equal(http.StatusOK, result.StatusCode)
+			s.Equal("2", result.Header.Get("RateLimit-Limit"))
+			s.Equal(strconv.Itoa(remaining), result.Header.Get("RateLimit-Remaining"))
+			s.Equal("2;w=60", result.Header.Get("RateLimit-Policy"))
+		}
+
+		result := s.serve(s.route, request("192.0.2.1:1234"))
+
+		s.Equal(http.StatusTooManyRequests, result.StatusCode)
+		s.Equal("30", result.Header.Get("Retry-After"))
+		s.Equal("60", result.Header.Get("RateLimit-Reset"))
+	})
+
+	s.T().Run("TestRateLimiterGivenExhaustedBucketWhenTimePassesThenItShouldRefill", func(t *testing.T) {
+		s.now = s.now.Add(30 * time.Second)
+
+		s.Equal(http.StatusOK, s.serve(s.route, request("192.0.2.1:1234")).StatusCode)
+		s.Equal(http.StatusTooManyRequests, s.serve(s.route, request("192.0.2.1:1234")).StatusCode)
+	})
+
+	s.T().Run("TestRateLimiterGivenExhaustedBucketWhenAnotherClientOrRouteIsServedThenItShouldNotBeLimited", func(t *testing.T) {
+		s.Equal(http.StatusOK, s.serve(s.route, request("192.0.2.2:1234")).StatusCode)
+		s.Equal(http.StatusOK, s.serve(model.Route{Name: "GET TODO LIST"}, request("192.0.2.1:1234")).StatusCode)
+	})
+
+	s.T().Run("TestRateLimiterGivenAuthenticatedCallersOnOneAddressWhenTheyAreServedThenTheyShouldHaveTheirOwnBuckets", func(t *testing.T) {
+		for _, identity := range []auth.Identity{{UserID: 1}, {UserID: 2}, {UserID: 1, APIKeyID: 1}} {
+			r := request("192.0.2.1:1234")
+			r = r.WithContext(auth.WithIdentity(r.Context(), identity))
+
+			s.Equal(http.StatusOK, s.serve(s.route, r).StatusCode)
+		}
+	})
+
+	s.T().Run("TestRateLimiterGivenZeroLimitWhenARequestIsServedThenItShouldNotBeLimited", func(t *testing.T) {
+		limiter := NewRateLimiter(model.RateLimitConfig{}, nil)
+		w := httptest.NewRecorder()
+		limiter.Limit(s.route)(http.NotFoundHandler()).ServeHTTP(w, request("192.0.2.1:1234"))
+
+		s.Empty(w.Header().Get("RateLimit-Limit"))
+	})
+}
+
+func (s *RateLimiterSuite) TestRateLimiterGivenWhenAuthenticationFails() {
+	serve := func(status int) (*http.Response, bool) {
+		served := false
+		w := httptest.NewRecorder()
+		s.limiter.LimitFailedAuthentication(s.route)(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			served = true
+			rw.WriteHeader(status)
+		})).ServeHTTP(w, request("192.0.2.1:1234"))
+		return w.Result(), served
+	}
+
+	s.T().Run("TestRateLimiterGivenAuthenticatedRequestsWhenTheyAreServedThenTheyShouldNotUseTheBudget", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			result, _ := serve(http.StatusOK)
+			s.Equal(http.StatusOK, result.StatusCode)
+		}
+	})
+
+	s.T().Run("TestRateLimiterGivenExhaustedBudgetWhenCredentialsAreSentThenTheyShouldBeRejectedWithoutBeingChecked", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			result, _ := serve(http.StatusUnauthorized)
+			s.Equal(http.StatusUnauthorized, result.StatusCode)
+		}
+
+		result, served := serve(http.StatusOK)
+
+		s.Equal(http.StatusTooManyRequests, result.StatusCode)
+		s.False(served)
+	})
+	s.T().Run("TestRateLimiterGivenMoreConcurrentValidRequestsThanTheBurstWhenTheyAreServedThenNoneShouldBeRejected", func(t *testing.T) {
+		s.SetupTest()
+
+		const concurrent = 10
+		inFlight, release := int32(0), make(chan struct{})
+		handler := s.limiter.LimitFailedAuthentication(s.route)(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&inFlight, 1)
+			<-release
+			rw.WriteHeader(http.StatusOK)
+		}))
+
+		statuses := make(chan int, concurrent)
+		for i := 0; i < concurrent; i++ {
+			go func() {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, request("192.0.2.1:1234"))
+				statuses <- w.Code
+			}()
+		}
+
+		s.Eventually(func() bool { return atomic.LoadInt32(&inFlight) == concurrent }, time.Second, time.Millisecond)
+		close(release)
+		for i := 0; i < concurrent; i++ {
+			s.Equal(http.StatusOK, <-statuses)
+		}
+	})
+
+	s.T().Run("TestRateLimiterGivenConcurrentFailuresBeyondTheBurstWhenTheyAreAnsweredThenEachShouldBePaidFor", func(t *testing.T) {
+		s.SetupTest()
+
+		const concurrent = 4
+		entered := sync.WaitGroup{}
+		entered.Add(concurrent)
+		handler := s.limiter.LimitFailedAuthentication(s.route)(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			entered.Done()
+			entered.Wait()
+			rw.WriteHeader(http.StatusUnauthorized)
+		}))
+
+		done := sync.WaitGroup{}
+		for i := 0; i < concurrent; i++ {
+			done.Add(1)
+			go func() {
+				defer done.Done()
+				handler.ServeHTTP(httptest.NewRecorder(), request("192.0.2.1:1234"))
+			}()
+		}
+		done.Wait()
+
+		// Four failures leave the bucket of 2 two tokens in debt, so the next
+		// request waits three token intervals of 30s instead of one.
+		s.now = s.now.Add(85 * time.Second)
+		result, served := serve(http.StatusOK)
+		s.Equal(http.StatusTooManyRequests, result.StatusCode)
+		s.False(served)
+
+		s.now = s.now.Add(10 * time.Second)
+		result, served = serve(http.StatusOK)
+		s.Equal(http.StatusOK, result.StatusCode)
+		s.True(served)
+	})
+}
+
+func (s *RateLimiterSuite) TestRateLimiterGivenWhenTheClientIPIsResolved() {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.10"})
+	s.NoError(err)
+
+	for _, tc := range []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{"TestClientIPGivenUntrustedPeerWhenItSendsXForwardedForThenTheHeaderShouldBeIgnored", "198.51.100.7:1234", "203.0.113.9", "198.51.100.7"},
+		{"TestClientIPGivenTrustedProxyWhenItSendsXForwardedForThenTheForwardedClientShouldBeUsed", "10.0.0.1:1234", "203.0.113.9", "203.0.113.9"},
+		{"TestClientIPGivenChainOfProxiesWhenItIsResolvedThenTheFirstUntrustedHopFromTheRightShouldBeUsed", "10.0.0.1:1234", "6.6.6.6, 203.0.113.9, 192.0.2.10", "203.0.113.9"},
+		{"TestClientIPGivenTrustedProxyWithoutHeaderWhenItIsResolvedThenTheProxyShouldBeUsed", "10.0.0.1:1234", "", "10.0.0.1"},
+	} {
+		s.T().Run(tc.name, func(t *testing.T) {
+			r := request(tc.remoteAddr)
+			if tc.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+
+			s.Equal(tc.expected, proxies.ClientIP(r))
+		})
+	}
+
+	s.T().Run("TestParseTrustedProxiesGivenInvalidEntryWhenItIsParsedThenItShouldReturnAnError", func(t *testing.T) {
+		_, err := ParseTrustedProxies([]string{"not-an-ip"})
+
+		s.Error(err)
+	})
+}
@@ -1,23 +1,60 @@
 package server
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"runtime/debug"
 
 	"github.com/gorilla/mux"
+	"github.com/tarkanaciksoz/api-todo-app/internal/apikey"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/metrics"
 	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/storage"
 	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+	"github.com/tarkanaciksoz/api-todo-app/internal/tracing"
+	"github.com/tarkanaciksoz/api-todo-app/internal/user"
 	"github.com/tarkanaciksoz/api-todo-app/internal/util"
 )
 
-func Init(logger *log.Logger) *mux.Router {
-	db := todo.NewDB()
-	todoService := todo.NewTodoService(logger, db)
+// Init builds the handler serving every route. Readiness reported by health
+// depends on the todo store and m records the traffic and the store once Init
+// returns; m is served apart, by NewInternalHandler.
+func Init(logger *slog.Logger, config model.Config, store *storage.Storage, health *Health, m *metrics.Metrics) (http.Handler, error) {
+	tokens, err := auth.NewJWT(config)
+	if err != nil {
+		return nil, err
+	}
+
+	cors, err := NewCORS(config.CORS)
+	if err != nil {
+		return nil, err
+	}
+
+	proxies, err := ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	limiter := NewRateLimiter(config.RateLimit, proxies)
+
+	tracer := tracing.Tracer()
+
+	// Probes and scrapes use the store itself so that they show up in
+	// neither the spans nor the store metrics of requests.
+	health.AddCheck("database", store.Todos.Ping)
+	m.CountTodos(store.Todos)
+	todos := tracing.TraceDB(tracer, m.InstrumentDB(store.Todos))
+
+	todoService := tracing.TraceService(tracer, todo.NewTodoService(logger, todos))
 	todoHandler := todo.NewTodoHandler(todoService)
 
+	userService := tracing.TraceUserService(tracer, user.NewUserService(logger, tracing.TraceUserDB(tracer, store.Users), tokens))
+	userHandler := user.NewUserHandler(userService)
+
+	apiKeyService := tracing.TraceAPIKeyService(tracer, apikey.NewAPIKeyService(logger, tracing.TraceAPIKeyDB(tracer, store.Keys)))
+	apiKeyHandler := apikey.NewAPIKeyHandler(apiKeyService)
+
 	mappedRoutes := make(map[string]model.Routes)
 	mappedRoutes[http.MethodGet] = model.Routes{
 		model.Route{
@@ -25,12 +62,35 @@ func Init(logger *log.Logger) *mux.Router {
 			Method:      http.MethodGet,
 			Pattern:     "/todo/{id:[0-9]+}",
 			HandlerFunc: todoHandler.GetTodo,
+			Scope:       auth.ScopeTodosRead,
 		},
 		model.Route{
 			Name:        "GET TODO LIST",
 			Method:      http.MethodGet,
 			Pattern:     "/todo",
 			HandlerFunc: todoHandler.ListTodos,
+			Scope:       auth.ScopeTodosRead,
+		},
+		model.Route{
+			Name:        "HEALTHZ",
+			Method:      http.MethodGet,
+			Pattern:     "/healthz",
+			HandlerFunc: health.Live,
+			Public:      true,
+		},
+		model.Route{
+			Name:        "READYZ",
+			Method:      http.MethodGet,
+			Pattern:     "/readyz",
+			HandlerFunc: health.Ready,
+			Public:      true,
+		},
+		model.Route{
+			Name:        "GET API KEY LIST",
+			Method:      http.MethodGet,
+			Pattern:     "/api-keys",
+			HandlerFunc: apiKeyHandler.ListAPIKeys,
+			Scope:       auth.ScopeAPIKeys,
 		},
 	}
 
@@ -40,6 +100,35 @@ func Init(logger *log.Logger) *mux.Router {
 			Method:      http.MethodPost,
 			Pattern:     "/todo",
 			HandlerFunc: todoHandler.CreateTodo,
+			Scope:       auth.ScopeTodosWrite,
+		},
+		model.Route{
+			Name:        "TRANSITION TODO",
+			Method:      http.MethodPost,
+			Pattern:     "/todo/{id:[0-9]+}/transitions",
+			HandlerFunc: todoHandler.TransitionTodo,
+			Scope:       auth.ScopeTodosWrite,
+		},
+		model.Route{
+			Name:        "REGISTER USER",
+			Method:      http.MethodPost,
+			Pattern:     "/users",
+			HandlerFunc: userHandler.Register,
+			Public:      true,
+		},
+		model.Route{
+			Name:        "LOGIN USER",
+			Method:      http.MethodPost,
+			Pattern:     "/login",
+			HandlerFunc: userHandler.Login,
+			Public:      true,
+		},
+		model.Route{
+			Name:        "CREATE NEW API KEY",
+			Method:      http.MethodPost,
+			Pattern:     "/api-keys",
+			HandlerFunc: apiKeyHandler.CreateAPIKey,
+			Scope:       auth.ScopeAPIKeys,
 		},
 	}
 
@@ -49,31 +138,34 @@ func Init(logger *log.Logger) *mux.Router {
 			Method:      http.MethodPut,
 			Pattern:     "/todo/{id:[0-9]+}",
 			HandlerFunc: todoHandler.MarkTodo,
+			Scope:       auth.ScopeTodosWrite,
 		},
 	}
 
-	mappedRoutes[http.MethodDelete] = model.Routes{
+	mappedRoutes[http.MethodPatch] = model.Routes{
 		model.Route{
-			Name:        "DELETE TODO",
-			Method:      http.MethodDelete,
+			Name:        "PATCH TODO",
+			Method:      http.MethodPatch,
 			Pattern:     "/todo/{id:[0-9]+}",
-			HandlerFunc: todoHandler.DeleteTodo,
+			HandlerFunc: todoHandler.PatchTodo,
+			Scope:       auth.ScopeTodosWrite,
 		},
 	}
 
-	// added for cors
-	mappedRoutes[http.MethodOptions] = model.Routes{
+	mappedRoutes[http.MethodDelete] = model.Routes{
 		model.Route{
-			Name:        "CORS MARK - UNMARK TODO",
-			Method:      http.MethodOptions,
+			Name:        "DELETE TODO",
+			Method:      http.MethodDelete,
 			Pattern:     "/todo/{id:[0-9]+}",
-			HandlerFunc: OptionsHandler,
+			HandlerFunc: todoHandler.DeleteTodo,
+			Scope:       auth.ScopeTodosWrite,
 		},
 		model.Route{
-			Name:        "CORS DELETE TODO",
-			Method:      http.MethodOptions,
-			Pattern:     "/todo/{id:[0-9]+}",
-			HandlerFunc: OptionsHandler,
+			Name:        "REVOKE API KEY",
+			Method:      http.MethodDelete,
+			Pattern:     "/api-keys/{id:[0-9]+}",
+			HandlerFunc: apiKeyHandler.RevokeAPIKey,
+			Scope:       auth.ScopeAPIKeys,
 		},
 	}
 
@@ -83,62 +175,64 @@ func Init(logger *log.Logger) *mux.Router {
 
 	for method, routes := range mappedRoutes {
 		methodRout := router.Methods(method).Subrouter()
-		methodRout.Use(ApplicationRecovery)
 		methodRout.Use(Middleware)
+		methodRout.Use(RequestTimeout(config.RequestTimeout))
 		for _, route := range routes {
-			methodRout.HandleFunc(route.Pattern, route.HandlerFunc)
+			var handler http.Handler = route.HandlerFunc
+			if route.Public {
+				handler = limiter.Limit(route)(handler)
+			} else {
+				handler = recordUser(handler)
+				handler = Authorize(route.Scope)(handler)
+				handler = limiter.Limit(route)(handler)
+				handler = Authenticate(tokens)(handler)
+				handler = APIKeyAuthentication(apiKeyService)(handler)
+				handler = limiter.LimitFailedAuthentication(route)(handler)
+			}
+			// Recovery goes inside the instrumentation so that panics count
+			// as 500 responses in metrics and spans.
+			handler = ApplicationRecovery(logger)(handler)
+			handler = m.InstrumentRoute(route.Name, handler)
+			handler = Trace(tracer, route)(handler)
+			methodRout.Handle(route.Pattern, recordRoute(route)(handler))
 		}
 	}
 
-	return router
+	return RequestID(AccessLog(logger, proxies)(cors.Handler(router))), nil
 }
 
-func MethodNotFoundHandler(logger *log.Logger) http.Handler {
+func MethodNotFoundHandler(logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		logger.Printf("No Route Found With Pattern : %s", r.URL.Path)
+		logger.DebugContext(r.Context(), "no route found", "path", r.URL.Path)
 
 		rw.Header().Add("Content-Type", "application/json")
-		rw.Header().Add("Access-Control-Allow-Origin", "*")
-		rw.Header().Add("Access-Control-Allow-Headers", "*")
-		rw.Header().Add("Access-Control-Allow-Credentials", "true")
-		rw.WriteHeader(http.StatusOK)
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Method Not Found", nil, http.StatusNotFound))
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Method Not Found", nil, http.StatusNotFound))
 	})
 }
 
-func MethodNotAllowedHandler(logger *log.Logger) http.Handler {
+func MethodNotAllowedHandler(logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		logger.Printf("Method %s Not Allowed For Pattern : %s", r.Method, r.URL.Path)
+		logger.DebugContext(r.Context(), "method not allowed", "method", r.Method, "path", r.URL.Path)
 
 		rw.Header().Add("Content-Type", "application/json")
-		rw.Header().Add("Access-Control-Allow-Origin", "*")
-		rw.Header().Add("Access-Control-Allow-Headers", "*")
-		rw.Header().Add("Access-Control-Allow-Credentials", "true")
-		rw.WriteHeader(http.StatusOK)
-		json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Method Not Allowed", nil, http.StatusBadRequest))
+		util.WriteResponse(rw, util.SetAndGetResponse(false, "Method Not Allowed", nil, http.StatusMethodNotAllowed))
 	})
 }
 
-func ApplicationRecovery(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if err := recover(); err != nil {
-				_, err := fmt.Fprintln(os.Stderr, "Recovered from application error occurred")
-				if err != nil {
-					rw.WriteHeader(http.StatusOK)
-					json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Internal Server Error at Application Recovery", nil, http.StatusInternalServerError))
-					return
-				}
-				_, _ = fmt.Fprintln(os.Stderr, err)
+// ApplicationRecovery turns panics of handlers into 500 responses and logs
+// them with their stack trace.
+func ApplicationRecovery(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if err := recover(); err != nil {
+					logger.ErrorContext(r.Context(), "recovered from panic", "panic", fmt.Sprint(err), "stack", string(debug.Stack()))
 
-				rw.WriteHeader(http.StatusOK)
-				json.NewEncoder(rw).Encode(util.SetAndGetResponse(false, "Internal Server Error", nil, http.StatusInternalServerError))
-				return
-			}
-		}()
+					util.WriteResponse(rw, util.SetAndGetResponse(false, "Internal Server Error", nil, http.StatusInternalServerError))
+				}
+			}()
 
-		ctx := r.Context()
-		r = r.WithContext(ctx)
-		next.ServeHTTP(rw, r)
-	})
+			next.ServeHTTP(rw, r)
+		})
+	}
 }
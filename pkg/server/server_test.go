@@ -0,0 +1,661 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/apperr"
+	"github.com/tarkanaciksoz/api-todo-app/internal/auth"
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/metrics"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/storage"
+	"github.com/tarkanaciksoz/api-todo-app/internal/todo"
+)
+
+var testConfig = model.Config{
+	TokenTTL:     time.Hour,
+	JWTAlgorithm: auth.AlgorithmHS256,
+	JWTSecret:    "server-test-secret-of-at-least-32-bytes",
+	CORS: model.CORSConfig{
+		AllowedOrigins: []string{"http://localhost:*"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	},
+}
+
+type ServerSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	router http.Handler
+	m      *metrics.Metrics
+	health *Health
+	token  string
+}
+
+func TestServerSuite(t *testing.T) {
+	suite.Run(t, new(ServerSuite))
+}
+
+func (s *ServerSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.m = metrics.New()
+	s.health = NewHealth(logging.Discard())
+	router, err := Init(logging.Discard(), testConfig, storage.NewMemory(), s.health, s.m)
+	s.NoError(err)
+	s.router = router
+	s.token = s.login("alice")
+}
+
+// login registers username and returns a bearer token for it.
+func (s *ServerSuite) login(username string) string {
+	credentials := `{"username":"` + username + `","password":"correct horse"}`
+
+	result, _ := s.serveAs(httptest.NewRequest(http.MethodPost, "/users", bytes.NewBufferString(credentials)), "")
+	s.Equal(http.StatusOK, result.StatusCode)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(credentials)))
+	s.Equal(http.StatusOK, w.Code)
+
+	response := struct {
+		Data model.Token `json:"data"`
+	}{}
+	s.NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.NotEmpty(response.Data.AccessToken)
+	return response.Data.AccessToken
+}
+
+func (s *ServerSuite) serve(r *http.Request) (*http.Response, model.Response) {
+	return s.serveAs(r, s.token)
+}
+
+func (s *ServerSuite) serveAs(r *http.Request, token string) (*http.Response, model.Response) {
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, r)
+
+	response := model.Response{}
+	s.NoError(json.NewDecoder(w.Result().Body).Decode(&response))
+	return w.Result(), response
+}
+
+func (s *ServerSuite) TestServerGivenWhenARequestIsServed() {
+	s.T().Run("TestServerGivenValidTodoWhenCreateTodoIsServedThenTheStatusShouldBe200", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"buy some milk"}`))
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusOK, result.StatusCode)
+		s.Equal(http.StatusOK, response.Code)
+		s.True(response.Success)
+	})
+
+	s.T().Run("TestServerGivenInvalidJSONWhenCreateTodoIsServedThenTheStatusShouldBe400", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{`))
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusBadRequest, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenInvalidTodoWhenCreateTodoIsServedThenTheStatusShouldBe422WithFieldDetails", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"  ","marked":42}`))
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusUnprocessableEntity, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+		s.Equal([]interface{}{
+			map[string]interface{}{"field": "value", "message": "must not be empty"},
+			map[string]interface{}{"field": "marked", "message": "must be 0 or 1"},
+		}, response.Data)
+	})
+
+	s.T().Run("TestServerGivenUnknownFieldWhenCreateTodoIsServedThenTheStatusShouldBe422", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"buy some milk","done":true}`))
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusUnprocessableEntity, result.StatusCode)
+		s.Equal([]interface{}{map[string]interface{}{"field": "done", "message": "unknown field"}}, response.Data)
+	})
+
+	s.T().Run("TestServerGivenOversizeBodyWhenMarkTodoIsServedThenTheStatusShouldBe413", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPut, "/todo/1", bytes.NewBufferString(`{"value":"`+strings.Repeat("a", apperr.MaxBodyBytes)+`"}`))
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusRequestEntityTooLarge, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenUnExistingTodoIdWhenGetTodoIsServedThenTheStatusShouldBe404", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/todo/999", nil)
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusNotFound, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+		s.Equal("no todo found with id:999", response.Message)
+	})
+
+	s.T().Run("TestServerGivenUnExistingTodoIdWhenDeleteTodoIsServedThenTheStatusShouldBe404", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodDelete, "/todo/999", nil)
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusNotFound, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenMergePatchWithOnlyMarkedWhenPatchTodoIsServedThenTheValueShouldBeKept", func(t *testing.T) {
+		s.serve(httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"enjoy the assignment"}`)))
+
+		r := httptest.NewRequest(http.MethodPatch, "/todo/2", bytes.NewBufferString(`{"marked":1}`))
+		r.Header.Set("Content-Type", "application/merge-patch+json")
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusOK, result.StatusCode)
+		data := response.Data.(map[string]interface{})
+		s.Equal(float64(2), data["id"])
+		s.Equal("enjoy the assignment", data["value"])
+		s.Equal(float64(1), data["marked"])
+		s.NotEmpty(data["completed_at"])
+	})
+
+	s.T().Run("TestServerGivenNullValueWhenPatchTodoIsServedThenTheStatusShouldBe422", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPatch, "/todo/2", bytes.NewBufferString(`{"value":null}`))
+		r.Header.Set("Content-Type", "application/merge-patch+json")
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusUnprocessableEntity, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenUnsupportedContentTypeWhenPatchTodoIsServedThenTheStatusShouldBe415", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPatch, "/todo/2", bytes.NewBufferString(`marked=1`))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusUnsupportedMediaType, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenUnknownRouteWhenItIsServedThenTheStatusShouldBe404", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusNotFound, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenUnsupportedMethodWhenItIsServedThenTheStatusShouldBe405", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPut, "/todo", nil)
+
+		result, response := s.serve(r)
+
+		s.Equal(http.StatusMethodNotAllowed, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenACrossOriginRequestIsServed() {
+	s.T().Run("TestServerGivenPreflightForAnyRouteWhenItIsServedThenItShouldBeAnsweredWithoutAuthentication", func(t *testing.T) {
+		for _, path := range []string{"/todo", "/todo/1", "/login", "/api-keys/1"} {
+			r := httptest.NewRequest(http.MethodOptions, path, nil)
+			r.Header.Set("Origin", "http://localhost:3000")
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, r)
+
+			s.Equal(http.StatusNoContent, w.Code, path)
+			s.Equal("http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"), path)
+		}
+	})
+
+	s.T().Run("TestServerGivenUnknownRouteWhenItIsServedCrossOriginThenTheErrorShouldCarryCORSHeaders", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+		r.Header.Set("Origin", "http://localhost:3000")
+
+		result, _ := s.serve(r)
+
+		s.Equal(http.StatusNotFound, result.StatusCode)
+		s.Equal("http://localhost:3000", result.Header.Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenARouteIsRateLimited() {
+	s.T().Run("TestServerGivenTooManyLoginsWhenLoginIsServedThenTheStatusShouldBe429InTheEnvelope", func(t *testing.T) {
+		config := testConfig
+		config.RateLimit = model.RateLimitConfig{Routes: map[string]model.RateLimit{"LOGIN USER": {Requests: 1, Per: time.Minute}}}
+		router, err := Init(logging.Discard(), config, storage.NewMemory(), NewHealth(logging.Discard()), metrics.New())
+		s.NoError(err)
+
+		for _, expected := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"username":"nobody","password":"wrong password"}`)))
+
+			response := model.Response{}
+			s.NoError(json.NewDecoder(w.Body).Decode(&response))
+			s.Equal(expected, w.Code)
+			s.Equal(expected, response.Code)
+		}
+	})
+
+	s.T().Run("TestServerGivenTooManyBogusAPIKeysWhenAProtectedRouteIsServedThenTheStatusShouldBe429", func(t *testing.T) {
+		config := testConfig
+		config.RateLimit = model.RateLimitConfig{Default: model.RateLimit{Requests: 2, Per: time.Minute}}
+		router, err := Init(logging.Discard(), config, storage.NewMemory(), NewHealth(logging.Discard()), metrics.New())
+		s.NoError(err)
+
+		for _, expected := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+			r := httptest.NewRequest(http.MethodGet, "/todo", nil)
+			r.Header.Set("Authorization", "ApiKey tda_bogus_key")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			s.Equal(expected, w.Code)
+		}
+	})
+}
+
+// panickingDB panics when todos are listed.
+type panickingDB struct {
+	todo.DB
+}
+
+func (panickingDB) List(context.Context, int, model.ListOptions) ([]*model.Todo, model.Pagination, error) {
+	panic("boom")
+}
+
+func (s *ServerSuite) TestServerGivenWhenMetricsAreScraped() {
+	s.T().Run("TestServerGivenServedRequestsWhenTheInternalHandlerIsScrapedThenTheyShouldBeCountedPerRoute", func(t *testing.T) {
+		result, _ := s.serve(httptest.NewRequest(http.MethodGet, "/todo", nil))
+		s.Equal(http.StatusOK, result.StatusCode)
+
+		w := httptest.NewRecorder()
+		NewInternalHandler(s.m, s.health).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		s.Equal(http.StatusOK, w.Code)
+		s.Contains(w.Body.String(), `todo_app_http_requests_total{code="200",method="get",route="GET TODO LIST"} 1`)
+		s.Contains(w.Body.String(), `todo_app_store_operation_duration_seconds_count{operation="list",outcome="ok"} 1`)
+	})
+
+	s.T().Run("TestServerGivenReadinessProbesWhenMetricsAreScrapedThenNeitherShouldCountStoreOperations", func(t *testing.T) {
+		s.SetupTest()
+		internal := NewInternalHandler(s.m, s.health)
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			internal.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			s.Equal(http.StatusOK, w.Code)
+		}
+
+		w := httptest.NewRecorder()
+		internal.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		s.NotContains(w.Body.String(), `operation="ping"`)
+		s.NotContains(w.Body.String(), `operation="count"`)
+	})
+
+	s.T().Run("TestServerGivenPanickingHandlerWhenMetricsAreScrapedThenItShouldBeCountedAs500", func(t *testing.T) {
+		store := storage.NewMemory()
+		store.Todos = panickingDB{store.Todos}
+		m := metrics.New()
+		router, err := Init(logging.Discard(), testConfig, store, NewHealth(logging.Discard()), m)
+		s.NoError(err)
+		s.router = router
+		s.token = s.login("alice")
+
+		result, _ := s.serve(httptest.NewRequest(http.MethodGet, "/todo", nil))
+		s.Equal(http.StatusInternalServerError, result.StatusCode)
+
+		w := httptest.NewRecorder()
+		NewInternalHandler(m, s.health).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		s.Contains(w.Body.String(), `todo_app_http_requests_total{code="500",method="get",route="GET TODO LIST"} 1`)
+	})
+
+	s.T().Run("TestServerGivenAPIHandlerWhenMetricsAreScrapedThenTheyShouldNotBeFound", func(t *testing.T) {
+		result, _ := s.serveAs(httptest.NewRequest(http.MethodGet, "/metrics", nil), "")
+
+		s.Equal(http.StatusNotFound, result.StatusCode)
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenHealthIsProbed() {
+	s.T().Run("TestServerGivenNoTokenWhenProbesAreServedThenTheyShouldReportTheStoreAsReady", func(t *testing.T) {
+		result, _ := s.serveAs(httptest.NewRequest(http.MethodGet, "/healthz", nil), "")
+		s.Equal(http.StatusOK, result.StatusCode)
+
+		result, response := s.serveAs(httptest.NewRequest(http.MethodGet, "/readyz", nil), "")
+		s.Equal(http.StatusOK, result.StatusCode)
+		s.Equal(map[string]interface{}{"database": "ok"}, response.Data)
+	})
+
+	s.T().Run("TestServerGivenInternalHandlerWhenProbesAreServedThenTheyShouldReportTheStoreAsReady", func(t *testing.T) {
+		for _, path := range []string{"/healthz", "/readyz"} {
+			w := httptest.NewRecorder()
+			NewInternalHandler(s.m, s.health).ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+			s.Equal(http.StatusOK, w.Code, path)
+		}
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenAuthenticationIsRequired() {
+	s.T().Run("TestServerGivenNoTokenWhenGetTodoListIsServedThenTheStatusShouldBe401", func(t *testing.T) {
+		result, response := s.serveAs(httptest.NewRequest(http.MethodGet, "/todo", nil), "")
+
+		s.Equal(http.StatusUnauthorized, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+		s.NotEmpty(result.Header.Get("WWW-Authenticate"))
+	})
+
+	s.T().Run("TestServerGivenUnknownTokenWhenGetTodoListIsServedThenTheStatusShouldBe401", func(t *testing.T) {
+		result, _ := s.serveAs(httptest.NewRequest(http.MethodGet, "/todo", nil), "not-a-token")
+
+		s.Equal(http.StatusUnauthorized, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenTokenSignedWithAnotherSecretWhenGetTodoListIsServedThenTheStatusShouldBe401", func(t *testing.T) {
+		config := testConfig
+		config.JWTSecret = "another-secret-of-at-least-32-bytes-long"
+		tokens, err := auth.NewJWT(config)
+		s.NoError(err)
+		token, err := tokens.Issue(&model.User{ID: 1})
+		s.NoError(err)
+
+		result, response := s.serveAs(httptest.NewRequest(http.MethodGet, "/todo", nil), token.AccessToken)
+
+		s.Equal(http.StatusUnauthorized, result.StatusCode)
+		s.Equal(auth.ErrInvalidToken.Error(), response.Message)
+	})
+
+	s.T().Run("TestServerGivenWrongPasswordWhenLoginIsServedThenTheStatusShouldBe401", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"username":"alice","password":"wrong password"}`))
+
+		result, _ := s.serveAs(r, "")
+
+		s.Equal(http.StatusUnauthorized, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenTakenUsernameWhenRegisterIsServedThenTheStatusShouldBe409", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/users", bytes.NewBufferString(`{"username":"alice","password":"correct horse"}`))
+
+		result, _ := s.serveAs(r, "")
+
+		s.Equal(http.StatusConflict, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenTodoOfAnotherUserWhenItIsRequestedThenTheStatusShouldBe404", func(t *testing.T) {
+		s.serve(httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"alice's todo"}`)))
+		mallory := s.login("mallory")
+
+		for _, r := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/todo/1", nil),
+			httptest.NewRequest(http.MethodPut, "/todo/1", bytes.NewBufferString(`{"id":1,"value":"mine now","marked":1}`)),
+			httptest.NewRequest(http.MethodDelete, "/todo/1", nil),
+		} {
+			result, _ := s.serveAs(r, mallory)
+			s.Equal(http.StatusNotFound, result.StatusCode, r.Method)
+		}
+
+		_, response := s.serveAs(httptest.NewRequest(http.MethodGet, "/todo", nil), mallory)
+		s.Empty(response.Data)
+
+		result, _ := s.serve(httptest.NewRequest(http.MethodGet, "/todo/1", nil))
+		s.Equal(http.StatusOK, result.StatusCode)
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenAnAPIKeyIsUsed() {
+	createKey := func(body string) (int, string) {
+		result, response := s.serve(httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString(body)))
+		s.Equal(http.StatusOK, result.StatusCode)
+
+		data := response.Data.(map[string]interface{})
+		return int(data["id"].(float64)), data["key"].(string)
+	}
+	serveWithKey := func(r *http.Request, key string) (*http.Response, model.Response) {
+		r.Header.Set("Authorization", "ApiKey "+key)
+		return s.serveAs(r, "")
+	}
+
+	s.T().Run("TestServerGivenKeyWithEveryScopeWhenCreateTodoIsServedThenTheTodoShouldBelongToTheKeyOwner", func(t *testing.T) {
+		_, key := createKey(`{"name":"cron"}`)
+
+		result, _ := serveWithKey(httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"created by cron"}`)), key)
+		s.Equal(http.StatusOK, result.StatusCode)
+
+		_, response := s.serve(httptest.NewRequest(http.MethodGet, "/todo/1", nil))
+		s.Equal("created by cron", response.Data.(map[string]interface{})["value"])
+	})
+
+	s.T().Run("TestServerGivenReadOnlyKeyWhenCreateTodoIsServedThenTheStatusShouldBe403", func(t *testing.T) {
+		_, key := createKey(`{"name":"reports","scopes":["todos:read"]}`)
+
+		result, _ := serveWithKey(httptest.NewRequest(http.MethodGet, "/todo", nil), key)
+		s.Equal(http.StatusOK, result.StatusCode)
+
+		result, response := serveWithKey(httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"not allowed"}`)), key)
+		s.Equal(http.StatusForbidden, result.StatusCode)
+		s.Equal(response.Code, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenKeyWhenAPIKeysAreManagedWithItThenTheStatusShouldBe403", func(t *testing.T) {
+		_, key := createKey(`{"name":"cron"}`)
+
+		result, _ := serveWithKey(httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString(`{"name":"escalated"}`)), key)
+		s.Equal(http.StatusForbidden, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenRevokedKeyWhenGetTodoListIsServedThenTheStatusShouldBe401", func(t *testing.T) {
+		id, key := createKey(`{"name":"leaked"}`)
+
+		result, _ := s.serve(httptest.NewRequest(http.MethodDelete, "/api-keys/"+strconv.Itoa(id), nil))
+		s.Equal(http.StatusOK, result.StatusCode)
+
+		result, _ = serveWithKey(httptest.NewRequest(http.MethodGet, "/todo", nil), key)
+		s.Equal(http.StatusUnauthorized, result.StatusCode)
+	})
+
+	s.T().Run("TestServerGivenKeysWhenListAPIKeysIsServedThenTheSecretsShouldNotBeReturned", func(t *testing.T) {
+		_, response := s.serve(httptest.NewRequest(http.MethodGet, "/api-keys", nil))
+
+		s.NotEmpty(response.Data)
+		for _, key := range response.Data.([]interface{}) {
+			s.NotContains(key, "key")
+			s.NotContains(key, "hash")
+		}
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenListTodosIsServed() {
+	for _, value := range []string{"buy some milk", "enjoy the assignment", "buy some bread"} {
+		s.serve(httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"`+value+`"}`)))
+	}
+
+	s.T().Run("TestServerGivenLimitAndFilterWhenListTodosIsServedThenItShouldReturnAPageWithPagination", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/todo?limit=1&q=buy&sort=value&order=desc", nil)
+		r.Header.Set("Authorization", "Bearer "+s.token)
+		s.router.ServeHTTP(w, r)
+
+		response := model.GetTodosResponse{}
+		s.NoError(json.NewDecoder(w.Result().Body).Decode(&response))
+		s.Equal(http.StatusOK, w.Result().StatusCode)
+		s.Len(response.Data, 1)
+		s.Equal(1, response.Data[0].ID)
+		s.Equal("buy some milk", response.Data[0].Value)
+		s.Equal(1, response.Pagination.Limit)
+		s.True(response.Pagination.HasMore)
+
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodGet, "/todo?limit=1&q=buy&sort=value&order=desc&cursor="+response.Pagination.NextCursor, nil)
+		r.Header.Set("Authorization", "Bearer "+s.token)
+		s.router.ServeHTTP(w, r)
+
+		response = model.GetTodosResponse{}
+		s.NoError(json.NewDecoder(w.Result().Body).Decode(&response))
+		s.Len(response.Data, 1)
+		s.Equal(3, response.Data[0].ID)
+		s.Equal("buy some bread", response.Data[0].Value)
+		s.False(response.Pagination.HasMore)
+	})
+
+	s.T().Run("TestServerGivenPriorityAndDueDateFiltersWhenListTodosIsServedThenOnlyMatchingTodosShouldBeReturned", func(t *testing.T) {
+		result, created := s.serve(httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(
+			`{"value":"plan the sprint","description":"with the whole team","due_date":"2030-01-01T09:00:00+03:00","priority":"high"}`)))
+		s.Equal(http.StatusOK, result.StatusCode)
+		data := created.Data.(map[string]interface{})
+		s.Equal("2030-01-01T06:00:00Z", data["due_date"])
+		s.Equal("high", data["priority"])
+		s.Equal("with the whole team", data["description"])
+		s.NotEmpty(data["created_at"])
+
+		for query, expected := range map[string]int{
+			"priority=high":                   1,
+			"priority=low":                    0,
+			"due_before=2030-01-01T07:00:00Z": 1,
+			"due_after=2030-01-01T06:00:00Z":  0,
+		} {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/todo?"+query, nil)
+			r.Header.Set("Authorization", "Bearer "+s.token)
+			s.router.ServeHTTP(w, r)
+
+			response := model.GetTodosResponse{}
+			s.NoError(json.NewDecoder(w.Result().Body).Decode(&response))
+			s.Equal(http.StatusOK, w.Result().StatusCode, query)
+			s.Len(response.Data, expected, query)
+		}
+	})
+
+	s.T().Run("TestServerGivenInvalidQueryWhenListTodosIsServedThenTheStatusShouldBe422", func(t *testing.T) {
+		for _, query := range []string{"limit=abc", "limit=100000", "marked=yes", "sort=priority", "priority=urgent", "due_before=tomorrow", "status=finished"} {
+			result, response := s.serve(httptest.NewRequest(http.MethodGet, "/todo?"+query, nil))
+
+			s.Equal(http.StatusUnprocessableEntity, result.StatusCode, query)
+			s.Equal(response.Code, result.StatusCode)
+		}
+	})
+	s.T().Run("TestServerGivenBothDueBoundsInvalidWhenListTodosIsServedThenDueBeforeShouldAlwaysBeReported", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			_, response := s.serve(httptest.NewRequest(http.MethodGet, "/todo?due_before=tomorrow&due_after=yesterday", nil))
+
+			s.Equal("due_before: must be an RFC 3339 date-time", response.Message)
+		}
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenATodoIsTransitioned() {
+	s.serve(httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"buy some milk"}`)))
+
+	s.T().Run("TestServerGivenAllowedStatusWhenTransitionTodoIsServedThenTheTodoShouldMove", func(t *testing.T) {
+		result, response := s.serve(httptest.NewRequest(http.MethodPost, "/todo/1/transitions", bytes.NewBufferString(`{"status":"done"}`)))
+
+		s.Equal(http.StatusOK, result.StatusCode)
+		data := response.Data.(map[string]interface{})
+		s.Equal("done", data["status"])
+		s.Equal(float64(1), data["marked"])
+	})
+
+	s.T().Run("TestServerGivenForbiddenStatusWhenTransitionTodoIsServedThenTheStatusShouldBe409", func(t *testing.T) {
+		result, response := s.serve(httptest.NewRequest(http.MethodPost, "/todo/1/transitions", bytes.NewBufferString(`{"status":"blocked"}`)))
+
+		s.Equal(http.StatusConflict, result.StatusCode)
+		s.Equal("todo can not move from done to blocked, allowed: open, archived", response.Message)
+	})
+
+	s.T().Run("TestServerGivenUnknownStatusWhenTransitionTodoIsServedThenTheStatusShouldBe422", func(t *testing.T) {
+		for _, body := range []string{`{"status":"finished"}`, `{"state":"open"}`} {
+			result, _ := s.serve(httptest.NewRequest(http.MethodPost, "/todo/1/transitions", bytes.NewBufferString(body)))
+
+			s.Equal(http.StatusUnprocessableEntity, result.StatusCode, body)
+		}
+	})
+
+	s.T().Run("TestServerGivenLegacyClientWhenMarkTodoIsServedThenMarkedShouldStillReopenTheTodo", func(t *testing.T) {
+		result, response := s.serve(httptest.NewRequest(http.MethodPut, "/todo/1", bytes.NewBufferString(`{"value":"buy some milk","marked":0}`)))
+
+		s.Equal(http.StatusOK, result.StatusCode)
+		data := response.Data.(map[string]interface{})
+		s.Equal("open", data["status"])
+		s.Equal(float64(0), data["marked"])
+	})
+
+	s.T().Run("TestServerGivenStatusFilterWhenListTodosIsServedThenOnlyMatchingTodosShouldBeReturned", func(t *testing.T) {
+		s.serve(httptest.NewRequest(http.MethodPost, "/todo", bytes.NewBufferString(`{"value":"enjoy the assignment","status":"in_progress"}`)))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/todo?status=in_progress", nil)
+		r.Header.Set("Authorization", "Bearer "+s.token)
+		s.router.ServeHTTP(w, r)
+
+		response := model.GetTodosResponse{}
+		s.NoError(json.NewDecoder(w.Result().Body).Decode(&response))
+		s.Len(response.Data, 1)
+		s.Equal(2, response.Data[0].ID)
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenRequestTimeoutIsApplied() {
+	s.T().Run("TestServerGivenTimeoutWhenRequestIsServedThenTheHandlerContextShouldHaveADeadline", func(t *testing.T) {
+		var deadline time.Time
+		handler := RequestTimeout(time.Second)(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			deadline, _ = r.Context().Deadline()
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todo", nil))
+
+		s.WithinDuration(time.Now().Add(time.Second), deadline, time.Second)
+	})
+
+	s.T().Run("TestServerGivenZeroTimeoutWhenRequestIsServedThenTheHandlerContextShouldHaveNoDeadline", func(t *testing.T) {
+		hasDeadline := true
+		handler := RequestTimeout(0)(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			_, hasDeadline = r.Context().Deadline()
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todo", nil))
+
+		s.False(hasDeadline)
+	})
+}
+
+func (s *ServerSuite) TestServerGivenWhenAHandlerPanics() {
+	s.T().Run("TestServerGivenPanickingHandlerWhenItIsServedThenTheStatusShouldBe500", func(t *testing.T) {
+		handler := ApplicationRecovery(logging.Discard())(Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/todo", nil))
+
+		response := model.Response{}
+		s.NoError(json.NewDecoder(w.Result().Body).Decode(&response))
+		s.Equal(http.StatusInternalServerError, w.Result().StatusCode)
+		s.Equal(http.StatusInternalServerError, response.Code)
+	})
+}
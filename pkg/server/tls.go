@@ -0,0 +1,155 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+// certCheckInterval is how often the certificate files are checked for
+// changes. Checks happen during handshakes, so an idle server checks less.
+const certCheckInterval = 10 * time.Second
+
+// NewTLSConfig returns the TLS configuration described by config, or nil when
+// no certificate is configured. Client certificates are required and verified
+// when config.ClientCAFile is set.
+func NewTLSConfig(config model.TLSConfig, logger *slog.Logger) (*tls.Config, error) {
+	if config.CertFile == "" && config.KeyFile == "" {
+		if config.ClientCAFile != "" {
+			return nil, errors.New("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		return nil, nil
+	}
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	reloader, err := NewCertReloader(config.CertFile, config.KeyFile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if config.ClientCAFile != "" {
+		pem, err := os.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading TLS_CLIENT_CA_FILE: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("TLS_CLIENT_CA_FILE holds no PEM certificate")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// CertReloader serves a certificate and key pair read from files and reads
+// them again once they are modified, so rotated certificates are picked up
+// without a restart. A pair that fails to load, as when only one of the files
+// was replaced yet, is logged and the previous one keeps being served.
+type CertReloader struct {
+	L *slog.Logger
+
+	certFile string
+	keyFile  string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTime  time.Time
+	checked  time.Time
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewCertReloader loads the pair at certFile and keyFile, failing when it is
+// not valid.
+func NewCertReloader(certFile, keyFile string, logger *slog.Logger) (*CertReloader, error) {
+	r := &CertReloader{
+		L:        logger,
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: certCheckInterval,
+		now:      time.Now,
+	}
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.checked) < r.interval {
+		return r.cert, nil
+	}
+	r.checked = now
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		r.L.Warn("certificate files unavailable, keeping the loaded certificate", "error", err)
+		return r.cert, nil
+	}
+	if modTime.Equal(r.modTime) {
+		return r.cert, nil
+	}
+
+	if err := r.load(modTime); err != nil {
+		r.L.Warn("certificate reload failed, keeping the loaded certificate", "error", err)
+		return r.cert, nil
+	}
+	r.L.Info("certificate reloaded", "cert_file", r.certFile)
+
+	return r.cert, nil
+}
+
+// load reads the pair and records modTime as the version it was read at.
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	r.cert, r.modTime = &cert, modTime
+	return nil
+}
+
+// latestModTime returns the latest modification time of the two files.
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	latest := time.Time{}
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,301 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/http2"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/logging"
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+)
+
+type TLSSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	dir    string
+	ca     *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	roots  *x509.CertPool
+	config model.Config
+}
+
+func TestTLSSuite(t *testing.T) {
+	suite.Run(t, new(TLSSuite))
+}
+
+func (s *TLSSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.dir = s.T().TempDir()
+
+	s.ca, s.caKey = nil, s.newKey()
+	s.ca = s.sign(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, &s.caKey.PublicKey)
+	s.write("ca.pem", "CERTIFICATE", s.ca.Raw)
+	s.roots = x509.NewCertPool()
+	s.roots.AddCert(s.ca)
+
+	s.issueServerCert("server-1")
+
+	// h2c connections left idle close a second after the GOAWAY of the
+	// shutdown, so the timeout leaves room for that.
+	s.config = model.Config{
+		ShutdownTimeout: 5 * time.Second,
+		Server: model.ServerConfig{
+			ReadTimeout:       time.Second,
+			ReadHeaderTimeout: time.Second,
+			WriteTimeout:      2 * time.Second,
+			IdleTimeout:       3 * time.Second,
+			MaxHeaderBytes:    4096,
+			HTTP2:             true,
+			TLS: model.TLSConfig{
+				CertFile: filepath.Join(s.dir, "server.pem"),
+				KeyFile:  filepath.Join(s.dir, "server-key.pem"),
+			},
+		},
+	}
+}
+
+func (s *TLSSuite) newKey() *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.NoError(err)
+	return key
+}
+
+// sign issues template for pub, signed by the CA or self-signed when there is
+// no CA yet.
+func (s *TLSSuite) sign(template *x509.Certificate, pub *ecdsa.PublicKey) *x509.Certificate {
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parent := s.ca
+	if parent == nil {
+		parent = template
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, parent, pub, s.caKey)
+	s.NoError(err)
+	cert, err := x509.ParseCertificate(raw)
+	s.NoError(err)
+	return cert
+}
+
+func (s *TLSSuite) write(name, blockType string, der []byte) string {
+	path := filepath.Join(s.dir, name)
+	s.NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func (s *TLSSuite) writeKey(name string, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalECPrivateKey(key)
+	s.NoError(err)
+	return s.write(name, "EC PRIVATE KEY", der)
+}
+
+// issueServerCert replaces server.pem and server-key.pem with a certificate
+// for 127.0.0.1 named commonName.
+func (s *TLSSuite) issueServerCert(commonName string) {
+	key := s.newKey()
+	cert := s.sign(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &key.PublicKey)
+
+	s.write("server.pem", "CERTIFICATE", cert.Raw)
+	s.writeKey("server-key.pem", key)
+}
+
+func (s *TLSSuite) clientCert() tls.Certificate {
+	key := s.newKey()
+	cert := s.sign(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &key.PublicKey)
+
+	pair, err := tls.LoadX509KeyPair(s.write("client.pem", "CERTIFICATE", cert.Raw), s.writeKey("client-key.pem", key))
+	s.NoError(err)
+	return pair
+}
+
+// start serves a handler answering with the protocol of the request and
+// returns the URL of the server.
+func (s *TLSSuite) start(scheme string) string {
+	srv, err := NewHTTPServer(logging.Discard(), s.config, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(r.Proto))
+	}))
+	s.NoError(err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, logging.Discard(), srv, listener, NewHealth(logging.Discard()), s.config)
+	}()
+	s.T().Cleanup(func() {
+		cancel()
+		s.NoError(<-done)
+	})
+
+	return scheme + "://" + listener.Addr().String()
+}
+
+func (s *TLSSuite) get(client *http.Client, url string) (string, error) {
+	response, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	return response.Proto, nil
+}
+
+func (s *TLSSuite) httpsClient(certs ...tls.Certificate) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: s.roots, Certificates: certs},
+		ForceAttemptHTTP2: true,
+	}}
+}
+
+func (s *TLSSuite) TestTLSGivenWhenTheServerIsBuilt() {
+	s.T().Run("TestTLSGivenServerSettingsWhenTheServerIsBuiltThenTheyShouldBeApplied", func(t *testing.T) {
+		srv, err := NewHTTPServer(logging.Discard(), s.config, http.NotFoundHandler())
+
+		s.NoError(err)
+		s.Equal(time.Second, srv.ReadTimeout)
+		s.Equal(time.Second, srv.ReadHeaderTimeout)
+		s.Equal(2*time.Second, srv.WriteTimeout)
+		s.Equal(3*time.Second, srv.IdleTimeout)
+		s.Equal(4096, srv.MaxHeaderBytes)
+		s.NotNil(srv.TLSConfig)
+	})
+
+	s.T().Run("TestTLSGivenCertificateWithoutKeyWhenTheServerIsBuiltThenItShouldReturnAnError", func(t *testing.T) {
+		config := s.config
+		config.Server.TLS.KeyFile = ""
+
+		_, err := NewHTTPServer(logging.Discard(), config, http.NotFoundHandler())
+		s.Error(err)
+	})
+
+	s.T().Run("TestTLSGivenClientCAWithoutCertificateWhenTheServerIsBuiltThenItShouldReturnAnError", func(t *testing.T) {
+		config := s.config
+		config.Server.TLS = model.TLSConfig{ClientCAFile: filepath.Join(s.dir, "ca.pem")}
+
+		_, err := NewHTTPServer(logging.Discard(), config, http.NotFoundHandler())
+		s.Error(err)
+	})
+}
+
+func (s *TLSSuite) TestTLSGivenWhenHTTP2IsConfigured() {
+	s.T().Run("TestTLSGivenHTTP2WhenARequestIsServedOverTLSThenItShouldBeNegotiated", func(t *testing.T) {
+		proto, err := s.get(s.httpsClient(), s.start("https"))
+
+		s.NoError(err)
+		s.Equal("HTTP/2.0", proto)
+	})
+
+	s.T().Run("TestTLSGivenHTTP2DisabledWhenARequestIsServedOverTLSThenHTTP1ShouldBeUsed", func(t *testing.T) {
+		s.config.Server.HTTP2 = false
+		defer func() { s.config.Server.HTTP2 = true }()
+
+		proto, err := s.get(s.httpsClient(), s.start("https"))
+
+		s.NoError(err)
+		s.Equal("HTTP/1.1", proto)
+	})
+
+	s.T().Run("TestTLSGivenHTTP2WithoutTLSWhenARequestIsServedInCleartextThenH2CShouldBeUsed", func(t *testing.T) {
+		s.config.Server.TLS = model.TLSConfig{}
+		client := &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}}
+
+		proto, err := s.get(client, s.start("http"))
+
+		s.NoError(err)
+		s.Equal("HTTP/2.0", proto)
+	})
+}
+
+func (s *TLSSuite) TestTLSGivenWhenMutualTLSIsRequired() {
+	s.T().Run("TestTLSGivenClientCAWhenClientsConnectThenOnlyThoseWithACertificateOfTheCAShouldBeServed", func(t *testing.T) {
+		s.config.Server.TLS.ClientCAFile = filepath.Join(s.dir, "ca.pem")
+		url := s.start("https")
+
+		_, err := s.get(s.httpsClient(), url)
+		s.Error(err)
+
+		proto, err := s.get(s.httpsClient(s.clientCert()), url)
+		s.NoError(err)
+		s.Equal("HTTP/2.0", proto)
+	})
+}
+
+func (s *TLSSuite) TestTLSGivenWhenTheCertificateIsRotated() {
+	s.T().Run("TestTLSGivenRotatedFilesWhenTheCheckIntervalElapsedThenTheNewCertificateShouldBeServed", func(t *testing.T) {
+		reloader, err := NewCertReloader(s.config.Server.TLS.CertFile, s.config.Server.TLS.KeyFile, logging.Discard())
+		s.NoError(err)
+		now := time.Now()
+		reloader.now = func() time.Time { return now }
+
+		s.Equal("server-1", s.commonName(reloader))
+
+		s.issueServerCert("server-2")
+		later := time.Now().Add(time.Minute)
+		s.NoError(os.Chtimes(s.config.Server.TLS.CertFile, later, later))
+
+		s.Equal("server-1", s.commonName(reloader))
+
+		now = now.Add(certCheckInterval)
+		s.Equal("server-2", s.commonName(reloader))
+	})
+
+	s.T().Run("TestTLSGivenHalfRotatedFilesWhenTheyAreCheckedThenThePreviousCertificateShouldBeKept", func(t *testing.T) {
+		reloader, err := NewCertReloader(s.config.Server.TLS.CertFile, s.config.Server.TLS.KeyFile, logging.Discard())
+		s.NoError(err)
+		name := s.commonName(reloader)
+
+		s.writeKey("server-key.pem", s.newKey())
+		later := time.Now().Add(2 * time.Minute)
+		s.NoError(os.Chtimes(s.config.Server.TLS.KeyFile, later, later))
+		reloader.now = func() time.Time { return later }
+
+		s.Equal(name, s.commonName(reloader))
+	})
+}
+
+func (s *TLSSuite) commonName(reloader *CertReloader) string {
+	cert, err := reloader.GetCertificate(nil)
+	s.NoError(err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	s.NoError(err)
+	return leaf.Subject.CommonName
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tarkanaciksoz/api-todo-app/internal/model"
+	"github.com/tarkanaciksoz/api-todo-app/internal/tracing"
+)
+
+type TracingSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	recorder *tracetest.SpanRecorder
+	tracer   trace.Tracer
+	route    model.Route
+}
+
+func TestTracingSuite(t *testing.T) {
+	suite.Run(t, new(TracingSuite))
+}
+
+func (s *TracingSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.recorder = tracetest.NewSpanRecorder()
+	s.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)).Tracer(tracing.Name)
+	s.route = model.Route{Name: "GET TODO", Method: http.MethodGet, Pattern: "/todo/{id:[0-9]+}"}
+}
+
+func (s *TracingSuite) serve(status int, r *http.Request) sdktrace.ReadOnlySpan {
+	handler := Trace(s.tracer, s.route)(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(status)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	spans := s.recorder.Ended()
+	s.Len(spans, 1)
+	return spans[0]
+}
+
+func (s *TracingSuite) TestTracingGivenWhenARouteIsServed() {
+	s.T().Run("TestTracingGivenTraceparentWhenARouteIsServedThenItsSpanShouldContinueTheClientTrace", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/todo/1", nil)
+		r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		span := s.serve(http.StatusOK, r)
+
+		s.Equal("GET /todo/{id:[0-9]+}", span.Name())
+		s.Equal(trace.SpanKindServer, span.SpanKind())
+		s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		s.Equal("00f067aa0ba902b7", span.Parent().SpanID().String())
+		s.True(span.Parent().IsRemote())
+		s.Contains(span.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+		s.Contains(span.Attributes(), attribute.String("route.name", "GET TODO"))
+	})
+
+	s.T().Run("TestTracingGivenServerErrorWhenARouteIsServedThenItsSpanShouldBeMarkedAsFailed", func(t *testing.T) {
+		s.recorder = tracetest.NewSpanRecorder()
+		s.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)).Tracer(tracing.Name)
+
+		span := s.serve(http.StatusInternalServerError, httptest.NewRequest(http.MethodGet, "/todo/1", nil))
+
+		s.False(span.Parent().IsValid())
+		s.Equal(codes.Error, span.Status().Code)
+	})
+}